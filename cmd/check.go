@@ -1,7 +1,13 @@
 // cmd/check.go
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"kuma-doctor/pkg/analysis"
+	"os"
+
+	"github.com/spf13/cobra"
+)
 
 // checkCmd representa el comando padre 'check' que agrupará otros subcomandos.
 var checkCmd = &cobra.Command{
@@ -10,7 +16,44 @@ var checkCmd = &cobra.Command{
 	Long:  `El comando 'check' agrupa todos los análisis individuales que se pueden ejecutar de forma no interactiva.`,
 }
 
+// newCheckCmd genera el subcomando 'check <id>' de un analizador registrado.
+func newCheckCmd(analyzer analysis.Analyzer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   analyzer.ID(),
+		Short: analyzer.Title(),
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprintf(os.Stderr, "Ejecutando análisis: %s...\n", analyzer.Title())
+			env, err := newEnv()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitAnalysisError)
+			}
+
+			ctx, cancel := newContext()
+			defer cancel()
+
+			// Un resultado por cada mesh seleccionado con --mesh
+			results, err := analysis.RunAnalyzer(ctx, env, analyzer)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, describeAnalysisError(err))
+				os.Exit(exitAnalysisError)
+			}
+
+			writeReport(ctx, results)
+		},
+	}
+	if aliaser, ok := analyzer.(analysis.Aliaser); ok {
+		cmd.Aliases = aliaser.Aliases()
+	}
+	return cmd
+}
+
 func init() {
 	// Añadimos el comando 'check' al comando raíz 'kuma-doctor'
 	rootCmd.AddCommand(checkCmd)
+
+	// Un subcomando por cada analizador registrado en pkg/analysis
+	for _, analyzer := range analysis.Analyzers() {
+		checkCmd.AddCommand(newCheckCmd(analyzer))
+	}
 }
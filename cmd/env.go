@@ -0,0 +1,105 @@
+// cmd/env.go
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kuma-doctor/internal/kubernetes"
+	"kuma-doctor/internal/kumaapi"
+	"kuma-doctor/pkg/analysis"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Flags de conexión compartidos por todos los comandos.
+var (
+	kubeOptions kubernetes.Options
+	cpOptions   kumaapi.Options
+	namespace   string
+	meshes      []string
+	fromDirs    []string
+	fromFiles   []string
+)
+
+// newEnv construye el entorno de análisis a partir de los flags globales.
+// Con --from-dir/--from-file los análisis se ejecutan contra los manifiestos cargados
+// en memoria, y con --cp-url contra la API REST del control plane (Universal), en lugar
+// de contra un clúster de Kubernetes.
+func newEnv() (*analysis.Env, error) {
+	source, err := newSource()
+	if err != nil {
+		return nil, err
+	}
+	// Todos los analizadores comparten una única instantánea: cada tipo de recurso se
+	// consulta una sola vez por ejecución.
+	return &analysis.Env{
+		Source:           analysis.NewSnapshot(source),
+		Namespace:        namespace,
+		Meshes:           meshes,
+		Concurrency:      concurrency,
+		CertExpiryWindow: certExpiry,
+	}, nil
+}
+
+// newContext devuelve el contexto de una ejecución: se cancela con Ctrl-C (o SIGTERM) y,
+// si se indicó --timeout, al agotarse ese tiempo.
+func newContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// describeAnalysisError traduce los errores de cancelación a un mensaje accionable.
+func describeAnalysisError(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("El análisis no terminó en el tiempo indicado con --timeout (%s).", timeout)
+	case errors.Is(err, context.Canceled):
+		return "Análisis cancelado."
+	default:
+		return fmt.Sprintf("Error durante el análisis: %v", err)
+	}
+}
+
+func newSource() (analysis.Source, error) {
+	offline := len(fromDirs) > 0 || len(fromFiles) > 0
+	if offline && cpOptions.URL != "" {
+		return nil, fmt.Errorf("--cp-url no se puede combinar con --from-dir/--from-file")
+	}
+
+	if offline {
+		client, warnings, err := kubernetes.NewOfflineClient(append(append([]string{}, fromDirs...), fromFiles...))
+		if err != nil {
+			return nil, fmt.Errorf("error al cargar los manifiestos: %w", err)
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Advertencia: %s\n", warning)
+		}
+		return kubernetes.NewSource(client, nil), nil
+	}
+
+	if cpOptions.URL != "" {
+		if cpOptions.Timeout <= 0 {
+			return nil, fmt.Errorf("--cp-timeout debe ser mayor que 0 (p. ej. 30s, 2m)")
+		}
+		source, err := kumaapi.NewSource(cpOptions)
+		if err != nil {
+			return nil, fmt.Errorf("error al conectar con el control plane: %w", err)
+		}
+		return source, nil
+	}
+
+	client, discoveryClient, err := kubernetes.NewClient(kubeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error al conectar con Kubernetes: %w", err)
+	}
+	return kubernetes.NewSource(client, discoveryClient), nil
+}
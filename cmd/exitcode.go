@@ -0,0 +1,65 @@
+// cmd/exitcode.go
+package cmd
+
+import (
+	"fmt"
+	"kuma-doctor/pkg/analysis"
+)
+
+// Códigos de salida documentados de kuma-doctor, pensados para pipelines de CI.
+const (
+	exitOK            = 0 // Sin hallazgos por encima del umbral de --fail-on.
+	exitWarnings      = 1 // El hallazgo más grave es WARN.
+	exitAlerts        = 2 // Hay al menos un hallazgo ALERT.
+	exitAnalysisError = 3 // Error de conexión, de análisis (aunque sea de un solo analizador) o de uso de la CLI.
+)
+
+// Valores aceptados por --fail-on.
+const (
+	failOnAlert = "alert"
+	failOnWarn  = "warn"
+	failOnNone  = "none"
+)
+
+// validateFailOn comprueba que el valor de --fail-on sea uno de los aceptados.
+func validateFailOn(value string) error {
+	switch value {
+	case failOnAlert, failOnWarn, failOnNone:
+		return nil
+	default:
+		return fmt.Errorf("valor inválido para --fail-on: %q (usa alert, warn o none)", value)
+	}
+}
+
+// exitCodeFor calcula el código de salida a partir de la severidad de los hallazgos
+// y del umbral configurado con --fail-on. Si algún análisis terminó con error el reporte
+// está incompleto y se devuelve exitAnalysisError, sea cual sea el umbral.
+func exitCodeFor(results []*analysis.ValidationResult, failOn string) int {
+	if analysis.HasErrors(results) {
+		return exitAnalysisError
+	}
+	if failOn == failOnNone {
+		return exitOK
+	}
+	highest, found := analysis.HighestSeverity(results)
+	if !found {
+		return exitOK
+	}
+
+	threshold := analysis.SeverityAlert
+	if failOn == failOnWarn {
+		threshold = analysis.SeverityWarn
+	}
+	if highest < threshold {
+		return exitOK
+	}
+
+	switch highest {
+	case analysis.SeverityAlert:
+		return exitAlerts
+	case analysis.SeverityWarn:
+		return exitWarnings
+	default:
+		return exitOK
+	}
+}
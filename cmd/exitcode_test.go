@@ -0,0 +1,68 @@
+// cmd/exitcode_test.go
+package cmd
+
+import (
+	"kuma-doctor/pkg/analysis"
+	"testing"
+)
+
+// resultWith devuelve un resultado con un hallazgo de cada severidad indicada.
+func resultWith(severities ...analysis.Severity) *analysis.ValidationResult {
+	result := &analysis.ValidationResult{Title: "prueba"}
+	for _, severity := range severities {
+		result.Findings = append(result.Findings, analysis.Finding{RuleID: "KD-TEST-001", Severity: severity})
+	}
+	return result
+}
+
+func TestExitCodeFor(t *testing.T) {
+	failed := &analysis.ValidationResult{Title: "con error", Status: analysis.StatusError, Reason: "timeout"}
+	skipped := &analysis.ValidationResult{Title: "omitido", Status: analysis.StatusSkipped, Reason: "no aplica"}
+
+	tests := []struct {
+		name    string
+		results []*analysis.ValidationResult
+		failOn  string
+		want    int
+	}{
+		{name: "sin resultados", failOn: failOnAlert, want: exitOK},
+		{name: "sin hallazgos", results: []*analysis.ValidationResult{resultWith()}, failOn: failOnWarn, want: exitOK},
+		{name: "solo INFO", results: []*analysis.ValidationResult{resultWith(analysis.SeverityInfo)}, failOn: failOnWarn, want: exitOK},
+		{name: "WARN con --fail-on alert", results: []*analysis.ValidationResult{resultWith(analysis.SeverityWarn)}, failOn: failOnAlert, want: exitOK},
+		{name: "WARN con --fail-on warn", results: []*analysis.ValidationResult{resultWith(analysis.SeverityInfo, analysis.SeverityWarn)}, failOn: failOnWarn, want: exitWarnings},
+		{
+			name:    "ALERT en cualquier resultado",
+			results: []*analysis.ValidationResult{resultWith(analysis.SeverityWarn), resultWith(analysis.SeverityAlert)},
+			failOn:  failOnAlert,
+			want:    exitAlerts,
+		},
+		{name: "ALERT con --fail-on warn", results: []*analysis.ValidationResult{resultWith(analysis.SeverityAlert)}, failOn: failOnWarn, want: exitAlerts},
+		{name: "ALERT con --fail-on none", results: []*analysis.ValidationResult{resultWith(analysis.SeverityAlert)}, failOn: failOnNone, want: exitOK},
+		{name: "un análisis con error", results: []*analysis.ValidationResult{resultWith(), failed}, failOn: failOnAlert, want: exitAnalysisError},
+		{
+			name:    "el error gana a los ALERT y a --fail-on none",
+			results: []*analysis.ValidationResult{resultWith(analysis.SeverityAlert), failed},
+			failOn:  failOnNone,
+			want:    exitAnalysisError,
+		},
+		{name: "un análisis omitido no es un error", results: []*analysis.ValidationResult{skipped}, failOn: failOnWarn, want: exitOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeFor(tt.results, tt.failOn); got != tt.want {
+				t.Errorf("exitCodeFor(--fail-on %s) = %d, se esperaba %d", tt.failOn, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFailOn(t *testing.T) {
+	for _, value := range []string{failOnAlert, failOnWarn, failOnNone} {
+		if err := validateFailOn(value); err != nil {
+			t.Errorf("validateFailOn(%q) = %v, se esperaba nil", value, err)
+		}
+	}
+	if err := validateFailOn("error"); err == nil {
+		t.Error("validateFailOn(\"error\") = nil, se esperaba un error")
+	}
+}
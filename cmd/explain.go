@@ -0,0 +1,66 @@
+// cmd/explain.go
+package cmd
+
+import (
+	"fmt"
+	"kuma-doctor/internal/report"
+	"kuma-doctor/pkg/analysis"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// explainCmd agrupa los comandos que explican cómo aplica Kuma la configuración a un recurso.
+var explainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Explica qué configuración de Kuma se aplica a un recurso",
+}
+
+var explainDataplaneCmd = &cobra.Command{
+	Use:     "dataplane <namespace>/<nombre>",
+	Aliases: []string{"dp"},
+	Short:   "Muestra las políticas que se aplican a un Dataplane y su configuración efectiva",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dpNamespace, dpName := splitResourceName(args[0])
+		env, err := newEnv()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitAnalysisError)
+		}
+
+		ctx, cancel := newContext()
+		defer cancel()
+
+		explanation, err := analysis.ExplainDataplane(ctx, env, dpNamespace, dpName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, describeAnalysisError(err))
+			os.Exit(exitAnalysisError)
+		}
+
+		output, err := report.GenerateExplanation(outputFormat, explanation)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error al generar el reporte: %v\n", err)
+			os.Exit(exitAnalysisError)
+		}
+		writeOutput(output)
+		if len(explanation.Diagnostics) > 0 {
+			os.Exit(exitAnalysisError)
+		}
+	},
+}
+
+// splitResourceName separa "<namespace>/<nombre>". Si el argumento no incluye namespace
+// (p. ej. en Universal) se usa el de --namespace.
+func splitResourceName(arg string) (string, string) {
+	if ns, name, found := strings.Cut(arg, "/"); found {
+		return ns, name
+	}
+	return namespace, arg
+}
+
+func init() {
+	explainCmd.AddCommand(explainDataplaneCmd)
+	rootCmd.AddCommand(explainCmd)
+}
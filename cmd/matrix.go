@@ -0,0 +1,47 @@
+// cmd/matrix.go
+package cmd
+
+import (
+	"fmt"
+	"kuma-doctor/internal/report"
+	"kuma-doctor/pkg/analysis"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var permissionMatrixCmd = &cobra.Command{
+	Use:     "permission-matrix",
+	Aliases: []string{"matrix"},
+	Short:   "Exporta la matriz de permisos origen × destino de todos los servicios del mesh",
+	Long: `Calcula, para cada par de servicios del mesh, la decisión de las MeshTrafficPermission
+(con la misma lógica que 'can-i-reach') y la exporta con --output txt, md, csv, json o html.
+Los pares permitidos por una regla 'from' de tipo 'kind: Mesh' se destacan aparte.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := newEnv()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitAnalysisError)
+		}
+
+		ctx, cancel := newContext()
+		defer cancel()
+
+		matrices, err := analysis.BuildPermissionMatrices(ctx, env)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, describeAnalysisError(err))
+			os.Exit(exitAnalysisError)
+		}
+
+		output, err := report.GenerateMatrix(outputFormat, matrices)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error al generar el reporte: %v\n", err)
+			os.Exit(exitAnalysisError)
+		}
+		writeOutput(output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(permissionMatrixCmd)
+}
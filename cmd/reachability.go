@@ -0,0 +1,63 @@
+// cmd/reachability.go
+package cmd
+
+import (
+	"fmt"
+	"kuma-doctor/internal/report"
+	"kuma-doctor/pkg/analysis"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Código de salida de 'can-i-reach' cuando el tráfico está denegado, igual que 'kubectl auth can-i'.
+const exitDenied = 1
+
+var (
+	reachFrom string
+	reachTo   string
+)
+
+var canIReachCmd = &cobra.Command{
+	Use:   "can-i-reach",
+	Short: "Simula si un servicio puede alcanzar a otro según las MeshTrafficPermission y el mTLS del mesh",
+	Long: `Evalúa las reglas 'from' de las MeshTrafficPermission que se aplican al servicio de destino,
+en el mismo orden de especificidad que el control plane, junto con el estado de mTLS del Mesh,
+y muestra el veredicto y la política que lo decide.
+
+Sale con código 0 si el tráfico está permitido, 1 si está denegado y 3 si no se pudo evaluar.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		env, err := newEnv()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitAnalysisError)
+		}
+
+		ctx, cancel := newContext()
+		defer cancel()
+
+		reachability, err := analysis.CanReach(ctx, env, reachFrom, reachTo)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, describeAnalysisError(err))
+			os.Exit(exitAnalysisError)
+		}
+
+		output, err := report.GenerateReachability(outputFormat, reachability)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error al generar el reporte: %v\n", err)
+			os.Exit(exitAnalysisError)
+		}
+		writeOutput(output)
+		if reachability.Verdict == analysis.VerdictDenied {
+			os.Exit(exitDenied)
+		}
+	},
+}
+
+func init() {
+	canIReachCmd.Flags().StringVar(&reachFrom, "from", "", "Servicio de origen (kuma.io/service o nombre del Service de Kubernetes)")
+	canIReachCmd.Flags().StringVar(&reachTo, "to", "", "Servicio de destino (kuma.io/service o nombre del Service de Kubernetes)")
+	_ = canIReachCmd.MarkFlagRequired("from")
+	_ = canIReachCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(canIReachCmd)
+}
@@ -2,8 +2,8 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"kuma-doctor/internal/kubernetes"
 	"kuma-doctor/internal/report"
 	"kuma-doctor/pkg/analysis"
 	"os"
@@ -15,58 +15,73 @@ var reportCmd = &cobra.Command{
 	Use:   "report",
 	Short: "Genera un reporte completo con todos los análisis disponibles",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Generando reporte completo, esto puede tardar un momento...")
-		client, err := kubernetes.NewClient()
+		fmt.Fprintln(os.Stderr, "Generando reporte completo, esto puede tardar un momento...")
+		env, err := newEnv()
 		if err != nil {
-			fmt.Printf("Error al conectar con Kubernetes: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitAnalysisError)
 		}
 
-		var allResults []*analysis.ValidationResult
+		ctx, cancel := newContext()
+		defer cancel()
 
-		// Ejecutamos cada análisis y añadimos su resultado a la lista
-		if result, err := analysis.AnalyzeSummary(client); err == nil {
-			allResults = append(allResults, result)
-		}
-		if result, err := analysis.AnalyzeDataplanes(client); err == nil {
-			allResults = append(allResults, result)
-		}
-		if result, err := analysis.AnalyzeTrafficPermissions(client); err == nil {
-			allResults = append(allResults, result)
-		}
-		if result, err := analysis.AnalyzeMTLS(client); err == nil {
-			allResults = append(allResults, result)
-		}
-		if result, err := analysis.AnalyzeResilience(client); err == nil {
-			allResults = append(allResults, result)
-		}
-		if result, err := analysis.AnalyzeObservability(client); err == nil {
-			allResults = append(allResults, result)
-		}
-
-		reporter, err := report.GetReporter(outputFormat)
+		// Ejecutamos todos los analizadores registrados en cada mesh y consolidamos sus resultados
+		allResults, err := analysis.RunAll(ctx, env, analysis.Analyzers())
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			fmt.Fprintln(os.Stderr, describeAnalysisError(err))
+			os.Exit(exitAnalysisError)
 		}
 
-		output, err := reporter.Generate(allResults)
-		if err != nil {
-			fmt.Printf("Error al generar el reporte: %v\n", err)
-			os.Exit(1)
-		}
+		writeReport(ctx, allResults)
+	},
+}
+
+// writeReport genera el reporte en el formato elegido, lo muestra o lo guarda en un archivo
+// y termina el proceso con el código de salida que corresponde a los hallazgos (ver --fail-on).
+// Solo el reporte se escribe en stdout; los mensajes de progreso y los errores van a stderr
+// para no corromper la salida JSON cuando se redirige.
+func writeReport(ctx context.Context, results []*analysis.ValidationResult) {
+	reporter, err := report.GetReporter(outputFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitAnalysisError)
+	}
 
-		if outputFile != "" {
-			err = os.WriteFile(outputFile, []byte(output), 0644)
-			if err != nil {
-				fmt.Printf("Error al escribir el archivo: %v\n", err)
-			} else {
-				fmt.Printf("Reporte guardado en %s\n", outputFile)
+	output, err := reporter.Generate(results)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error al generar el reporte: %v\n", err)
+		os.Exit(exitAnalysisError)
+	}
+	writeOutput(output)
+
+	// Un reporte incompleto (análisis con error, timeout o Ctrl-C) no puede dar el visto bueno.
+	for _, result := range results {
+		if result.Failed() {
+			if result.Mesh == "" {
+				fmt.Fprintf(os.Stderr, "Advertencia: %s: %s\n", result.Title, result.Reason)
+				continue
 			}
-		} else {
-			fmt.Println(output)
+			fmt.Fprintf(os.Stderr, "Advertencia: %s (mesh %s): %s\n", result.Title, result.Mesh, result.Reason)
 		}
-	},
+	}
+	if err := ctx.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, describeAnalysisError(err))
+		os.Exit(exitAnalysisError)
+	}
+	os.Exit(exitCodeFor(results, failOn))
+}
+
+// writeOutput muestra el reporte en stdout o lo guarda en el archivo indicado con --file.
+func writeOutput(output string) {
+	if outputFile == "" {
+		fmt.Println(output)
+		return
+	}
+	if err := os.WriteFile(outputFile, []byte(output), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error al escribir el archivo: %v\n", err)
+		os.Exit(exitAnalysisError)
+	}
+	fmt.Fprintf(os.Stderr, "Reporte guardado en %s\n", outputFile)
 }
 
 func init() {
@@ -3,8 +3,11 @@ package cmd
 
 import (
 	"fmt"
+	"kuma-doctor/internal/kumaapi"
 	"kuma-doctor/internal/tui"
+	"kuma-doctor/pkg/analysis"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -12,6 +15,10 @@ import (
 var (
 	outputFormat string
 	outputFile   string
+	failOn       string
+	timeout      time.Duration
+	concurrency  int
+	certExpiry   time.Duration
 )
 
 var rootCmd = &cobra.Command{
@@ -20,22 +27,58 @@ var rootCmd = &cobra.Command{
 	Long: `Una completa herramienta de diagnóstico que te permite revisar la salud
 y la configuración de tu Kuma service mesh de manera interactiva o a través
 de subcomandos para la automatización.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if concurrency < 1 {
+			return fmt.Errorf("--concurrency debe ser al menos 1 (recibido %d)", concurrency)
+		}
+		if certExpiry < 0 {
+			return fmt.Errorf("--cert-expiry-window no puede ser negativo (recibido %s)", certExpiry)
+		}
+		return validateFailOn(failOn)
+	},
 	// Si se ejecuta 'kuma-doctor' sin subcomandos, mostramos el menú.
 	Run: func(cmd *cobra.Command, args []string) {
 		// Ignora el error aquí, ya que el menú maneja su propio flujo
-		_ = tui.ShowInteractiveMenu(outputFormat, outputFile)
+		_ = tui.ShowInteractiveMenu(newContext, newEnv, outputFormat, outputFile)
 	},
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitAnalysisError)
 	}
 }
 
 func init() {
 	// Flags globales para todos los comandos
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "txt", "Formato del reporte (txt, md, json)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "txt", "Formato del reporte (txt, md, json; permission-matrix admite también csv y html)")
 	rootCmd.PersistentFlags().StringVarP(&outputFile, "file", "f", "", "Ruta del archivo para guardar el reporte (opcional)")
+	rootCmd.PersistentFlags().StringVar(&failOn, "fail-on", failOnNone, "Severidad mínima que provoca un código de salida distinto de 0 (alert, warn, none)")
+
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Tiempo máximo para completar el análisis (p. ej. 30s, 2m; 0 = sin límite)")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", 4, "Número máximo de análisis que se ejecutan en paralelo")
+	rootCmd.PersistentFlags().DurationVar(&certExpiry, "cert-expiry-window", analysis.DefaultCertExpiryWindow, "Avisa de los certificados mTLS de los proxies que caducan dentro de este plazo")
+
+	// Flags de conexión con el clúster, con la misma semántica que kubectl
+	rootCmd.PersistentFlags().StringVar(&kubeOptions.Kubeconfig, "kubeconfig", "", "Ruta al kubeconfig (por defecto $KUBECONFIG o ~/.kube/config)")
+	rootCmd.PersistentFlags().StringVar(&kubeOptions.Context, "context", "", "Contexto del kubeconfig a utilizar")
+	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "Limita el análisis a los Dataplanes de este namespace (por defecto todos)")
+	rootCmd.PersistentFlags().StringSliceVar(&meshes, "mesh", nil, "Mesh a analizar (se puede repetir o separar por comas; 'all' para todos, valor por defecto)")
+	rootCmd.PersistentFlags().StringVar(&kubeOptions.As, "as", "", "Usuario a impersonar en las peticiones al API server")
+	rootCmd.PersistentFlags().StringArrayVar(&kubeOptions.AsGroups, "as-group", nil, "Grupo a impersonar (se puede repetir)")
+
+	// Modo offline: analizar manifiestos exportados en lugar de un clúster
+	rootCmd.PersistentFlags().StringArrayVar(&fromDirs, "from-dir", nil, "Directorio con manifiestos YAML/JSON exportados a analizar sin conexión (se puede repetir)")
+	rootCmd.PersistentFlags().StringArrayVar(&fromFiles, "from-file", nil, "Archivo YAML/JSON o tarball (.tar, .tar.gz, .tgz) a analizar sin conexión (se puede repetir)")
+
+	// Modo Universal: leer los recursos de la API REST del control plane de Kuma
+	rootCmd.PersistentFlags().StringVar(&cpOptions.URL, "cp-url", "", "URL de la API del control plane de Kuma (p. ej. http://kuma-cp:5681) para despliegues Universal")
+	rootCmd.PersistentFlags().StringVar(&cpOptions.Token, "cp-token", "", "Token de usuario para la API del control plane")
+	rootCmd.PersistentFlags().StringVar(&cpOptions.TokenFile, "cp-token-file", "", "Archivo con el token de usuario para la API del control plane")
+	rootCmd.PersistentFlags().StringVar(&cpOptions.CACert, "cp-ca-cert", "", "Certificado de la CA con la que validar el TLS del control plane")
+	rootCmd.PersistentFlags().StringVar(&cpOptions.ClientCert, "cp-client-cert", "", "Certificado de cliente para la API del control plane (mTLS)")
+	rootCmd.PersistentFlags().StringVar(&cpOptions.ClientKey, "cp-client-key", "", "Clave del certificado de cliente para la API del control plane")
+	rootCmd.PersistentFlags().BoolVar(&cpOptions.InsecureSkipVerify, "cp-insecure-skip-verify", false, "No verificar el certificado TLS del control plane (inseguro)")
+	rootCmd.PersistentFlags().DurationVar(&cpOptions.Timeout, "cp-timeout", kumaapi.DefaultTimeout, "Tiempo máximo de cada petición a la API del control plane")
 }
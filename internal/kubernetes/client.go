@@ -2,29 +2,67 @@
 package kubernetes
 
 import (
-	"path/filepath"
+	"fmt"
 
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
 )
 
-// NewClient crea y devuelve un nuevo cliente dinámico de Kubernetes.
-func NewClient() (dynamic.Interface, error) {
-	var kubeconfig string
-	if home := homedir.HomeDir(); home != "" {
-		kubeconfig = filepath.Join(home, ".kube", "config")
-	}
+// Options define cómo se construye la conexión con el clúster. Los valores vacíos
+// significan "usar el comportamiento por defecto de kubectl".
+type Options struct {
+	Kubeconfig string   // Ruta explícita al kubeconfig; si está vacía se usa $KUBECONFIG o ~/.kube/config.
+	Context    string   // Contexto del kubeconfig a usar en lugar del actual.
+	As         string   // Usuario a impersonar (equivalente a kubectl --as).
+	AsGroups   []string // Grupos a impersonar (equivalente a kubectl --as-group).
+}
 
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+// NewClient crea y devuelve un nuevo cliente dinámico de Kubernetes y el cliente de
+// discovery con el que se resuelven las versiones de los CRDs de Kuma.
+func NewClient(opts Options) (dynamic.Interface, discovery.DiscoveryInterface, error) {
+	config, err := RESTConfig(opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dynamicClient, discoveryClient, nil
+}
+
+// RESTConfig resuelve la configuración de conexión siguiendo las mismas reglas que kubectl:
+// --kubeconfig, o bien la fusión de todos los archivos de $KUBECONFIG, o bien ~/.kube/config.
+// Si no hay ningún kubeconfig disponible, se usa la service account del pod (in-cluster).
+func RESTConfig(opts Options) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if opts.Kubeconfig != "" {
+		loadingRules.ExplicitPath = opts.Kubeconfig
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: opts.Context}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if clientcmd.IsEmptyConfig(err) && opts.Kubeconfig == "" && opts.Context == "" {
+		config, err = rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("no se encontró ningún kubeconfig y la configuración in-cluster no está disponible: %w", err)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return dynamicClient, nil
+	// La impersonación se aplica al final para que funcione también con la configuración in-cluster.
+	if opts.As != "" || len(opts.AsGroups) > 0 {
+		config.Impersonate = rest.ImpersonationConfig{UserName: opts.As, Groups: opts.AsGroups}
+	}
+	return config, nil
 }
@@ -0,0 +1,310 @@
+// internal/kubernetes/offline.go
+package kubernetes
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"kuma-doctor/pkg/analysis"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+// irregularResources cubre los kinds cuyo plural no se puede adivinar con las reglas de
+// meta.UnsafeGuessKindToResource (que convertiría "Mesh" en "meshs").
+var irregularResources = map[string]string{
+	"Mesh":      "meshes",
+	"Endpoints": "endpoints",
+}
+
+// NewOfflineClient carga manifiestos exportados (YAML o JSON, p. ej. la salida de
+// 'kubectl get ... -o yaml' o un repositorio GitOps) en un cliente dinámico en memoria,
+// de forma que los analizadores se ejecutan sin cambios contra esa instantánea.
+// Cada ruta puede ser un archivo, un directorio (se recorre recursivamente) o un
+// tarball (.tar, .tar.gz, .tgz).
+//
+// Los archivos que no se pueden decodificar (p. ej. plantillas de Helm sin renderizar en un
+// repositorio GitOps) se omiten y se devuelven como advertencias que los nombran.
+func NewOfflineClient(paths []string) (dynamic.Interface, []string, error) {
+	loader := &manifestLoader{}
+	var objects []*unstructured.Unstructured
+	for _, path := range paths {
+		loaded, err := loader.loadPath(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Una ruta sin recursos suele ser un volcado que ha fallado o una ruta equivocada; si
+		// se ignorase, el análisis pasaría sin errores contra una instantánea vacía.
+		if len(loaded) == 0 {
+			return nil, nil, fmt.Errorf("%s no contiene ningún recurso de Kubernetes", path)
+		}
+		objects = append(objects, loaded...)
+	}
+
+	// El cliente fake necesita conocer de antemano el List kind de cada recurso que se liste.
+	// Registramos todo el catálogo de analysis, para que los tipos sin objetos en la
+	// instantánea devuelvan una lista vacía, y los tipos de los objetos cargados.
+	listKinds := make(map[schema.GroupVersionResource]string)
+	for _, rt := range analysis.KnownResourceTypes() {
+		listKinds[rt.GVR] = rt.Kind + "List"
+	}
+	catalog := make(map[schema.GroupVersionResource]bool, len(listKinds))
+	for gvr := range listKinds {
+		catalog[gvr] = true
+	}
+	// Los recursos de Kubernetes que no son del catálogo (Pods, Services...) rara vez se
+	// exportan completos: solo se consideran exportados en los namespaces en los que aparece
+	// alguno, para que un namespace sin Pods en el volcado no parezca vacío.
+	exported := make(map[schema.GroupVersionResource]map[string]bool)
+	for _, obj := range objects {
+		gvr := resourceFor(obj)
+		listKinds[gvr] = obj.GetKind() + "List"
+		if !catalog[gvr] {
+			if exported[gvr] == nil {
+				exported[gvr] = make(map[string]bool)
+			}
+			exported[gvr][obj.GetNamespace()] = true
+		}
+	}
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	for _, obj := range objects {
+		gvr := resourceFor(obj)
+		// Los volcados suelen incluir el mismo objeto varias veces (p. ej. un directorio y un
+		// tarball con el mismo contenido); la última aparición prevalece.
+		err := client.Tracker().Create(gvr, obj, obj.GetNamespace())
+		if apierrors.IsAlreadyExists(err) {
+			err = client.Tracker().Update(gvr, obj, obj.GetNamespace())
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error al cargar %s %s/%s: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+
+	return &offlineClient{FakeDynamicClient: client, known: listKinds, exported: exported}, loader.warnings, nil
+}
+
+// offlineClient envuelve el cliente fake para que los recursos desconocidos se comporten
+// como en un clúster real (error NotFound, igual que un CRD no instalado) en lugar de
+// provocar un panic del cliente fake.
+type offlineClient struct {
+	*fake.FakeDynamicClient
+	known map[schema.GroupVersionResource]string
+	// exported son, para los tipos que no son del catálogo, los namespaces con algún objeto.
+	exported map[schema.GroupVersionResource]map[string]bool
+}
+
+func (c *offlineClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	if _, ok := c.known[gvr]; !ok {
+		return unknownResource{NamespaceableResourceInterface: c.FakeDynamicClient.Resource(gvr), gvr: gvr}
+	}
+	if namespaces, ok := c.exported[gvr]; ok {
+		return partialResource{NamespaceableResourceInterface: c.FakeDynamicClient.Resource(gvr), gvr: gvr, namespaces: namespaces}
+	}
+	return c.FakeDynamicClient.Resource(gvr)
+}
+
+// partialResource es un tipo exportado solo en algunos namespaces: en el resto se comporta
+// como un tipo desconocido en lugar de devolver una lista vacía.
+type partialResource struct {
+	dynamic.NamespaceableResourceInterface
+	gvr        schema.GroupVersionResource
+	namespaces map[string]bool
+}
+
+func (r partialResource) Namespace(namespace string) dynamic.ResourceInterface {
+	if namespace != "" && !r.namespaces[namespace] {
+		return unknownResource{NamespaceableResourceInterface: r.NamespaceableResourceInterface, gvr: r.gvr}
+	}
+	return r.NamespaceableResourceInterface.Namespace(namespace)
+}
+
+type unknownResource struct {
+	dynamic.NamespaceableResourceInterface
+	gvr schema.GroupVersionResource
+}
+
+func (r unknownResource) Namespace(string) dynamic.ResourceInterface { return r }
+
+func (r unknownResource) List(context.Context, metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return nil, apierrors.NewNotFound(r.gvr.GroupResource(), "")
+}
+
+func (r unknownResource) Get(_ context.Context, name string, _ metav1.GetOptions, _ ...string) (*unstructured.Unstructured, error) {
+	return nil, apierrors.NewNotFound(r.gvr.GroupResource(), name)
+}
+
+// resourceFor devuelve el GVR de un objeto cargado, priorizando el catálogo de analysis.
+func resourceFor(obj *unstructured.Unstructured) schema.GroupVersionResource {
+	gvk := obj.GroupVersionKind()
+	for _, rt := range analysis.KnownResourceTypes() {
+		if rt.Kind == gvk.Kind && rt.GVR.Group == gvk.Group {
+			return rt.GVR
+		}
+	}
+	if resource, ok := irregularResources[gvk.Kind]; ok {
+		return gvk.GroupVersion().WithResource(resource)
+	}
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	return gvr
+}
+
+// manifestLoader carga los objetos de archivos, directorios y tarballs, y acumula las
+// advertencias de los archivos que omite.
+type manifestLoader struct {
+	warnings []string
+}
+
+// skip registra un archivo que no se puede decodificar y se omite.
+func (l *manifestLoader) skip(name string, err error) {
+	l.warnings = append(l.warnings, fmt.Sprintf("se omite %s, que no es un manifiesto YAML/JSON válido: %v", name, err))
+}
+
+// loadPath carga los objetos de un archivo, directorio o tarball.
+func (l *manifestLoader) loadPath(path string) ([]*unstructured.Unstructured, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return l.loadFile(path)
+	}
+
+	var objects []*unstructured.Unstructured
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !(isManifest(p) || isTarball(p)) {
+			return nil
+		}
+		loaded, err := l.loadFile(p)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, loaded...)
+		return nil
+	})
+	return objects, err
+}
+
+func (l *manifestLoader) loadFile(path string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if isTarball(path) {
+		return l.loadTarball(path, f)
+	}
+	objects, err := decodeManifests(f)
+	if err != nil {
+		l.skip(path, err)
+		return nil, nil
+	}
+	return objects, nil
+}
+
+func (l *manifestLoader) loadTarball(path string, r io.Reader) ([]*unstructured.Unstructured, error) {
+	if strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".tgz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error al descomprimir %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var objects []*unstructured.Unstructured
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error al leer %s: %w", path, err)
+		}
+		if header.Typeflag != tar.TypeReg || !isManifest(header.Name) {
+			continue
+		}
+		loaded, err := decodeManifests(tr)
+		if err != nil {
+			l.skip(path+":"+header.Name, err)
+			continue
+		}
+		objects = append(objects, loaded...)
+	}
+	return objects, nil
+}
+
+// decodeManifests lee todos los documentos YAML/JSON de r, expandiendo los objetos 'List'.
+func decodeManifests(r io.Reader) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		// utiljson conserva los enteros como int64 (y no float64), igual que el API server,
+		// para que unstructured.NestedInt64 funcione sobre los puertos y demás campos numéricos.
+		var doc map[string]interface{}
+		if err := utiljson.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+		if len(doc) == 0 {
+			continue // Documento vacío (p. ej. un '---' final)
+		}
+
+		obj := &unstructured.Unstructured{Object: doc}
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				objects = append(objects, item.(*unstructured.Unstructured))
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			continue // No es un recurso de Kubernetes (p. ej. un kustomization.yaml o values.yaml)
+		}
+		objects = append(objects, obj)
+	}
+}
+
+func isManifest(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+func isTarball(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".tar") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
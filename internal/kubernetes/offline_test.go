@@ -0,0 +1,192 @@
+// internal/kubernetes/offline_test.go
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"kuma-doctor/pkg/analysis"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+const offlineDataplane = `
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: web-1, namespace: demo, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 80, tags: {kuma.io/service: web}}]}}
+`
+
+const offlinePods = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata: {name: web-1, namespace: demo}
+- apiVersion: v1
+  kind: Pod
+  metadata: {name: api-1, namespace: demo}
+`
+
+// writeFiles crea en un directorio temporal los archivos indicados (ruta relativa -> contenido).
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// tarball devuelve un .tar.gz con los archivos indicados.
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// listNames devuelve los nombres de los objetos de un tipo en un namespace, o el error.
+func listNames(client dynamic.Interface, rt analysis.ResourceType, namespace string) (string, error) {
+	list, err := client.Resource(rt.GVR).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return strings.Join(names, ","), nil
+}
+
+func TestNewOfflineClientTarball(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"dump.tgz": string(tarball(t, map[string]string{
+			"kuma/dataplanes.yaml": offlineDataplane,
+			"kube/pods.yaml":       offlinePods,
+			"README.md":            "no es un manifiesto",
+		})),
+	})
+
+	client, warnings, err := NewOfflineClient([]string{filepath.Join(dir, "dump.tgz")})
+	if err != nil {
+		t.Fatalf("NewOfflineClient: %v", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("advertencias inesperadas: %v", warnings)
+	}
+	if got, err := listNames(client, analysis.DataplaneType, "demo"); err != nil || got != "web-1" {
+		t.Errorf("Dataplanes = %q (%v), se esperaba web-1", got, err)
+	}
+	if got, err := listNames(client, analysis.PodType, "demo"); err != nil || got != "api-1,web-1" {
+		t.Errorf("Pods = %q (%v), se esperaba api-1,web-1 (expandiendo la List)", got, err)
+	}
+}
+
+func TestNewOfflineClientNamespaceScoping(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"all.yaml": offlineDataplane + "---" + offlinePods})
+	client, _, err := NewOfflineClient([]string{dir})
+	if err != nil {
+		t.Fatalf("NewOfflineClient: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		rt        analysis.ResourceType
+		namespace string
+		want      string
+		notFound  bool
+	}{
+		{name: "tipo del catálogo en un namespace sin objetos", rt: analysis.DataplaneType, namespace: "other"},
+		{name: "tipo del catálogo sin objetos", rt: analysis.MeshTimeoutType, namespace: "demo"},
+		{name: "Pods en un namespace exportado", rt: analysis.PodType, namespace: "demo", want: "api-1,web-1"},
+		{name: "Pods en un namespace no exportado", rt: analysis.PodType, namespace: "other", notFound: true},
+		{name: "Pods de todos los namespaces", rt: analysis.PodType, want: "api-1,web-1"},
+		{name: "tipo que no aparece en el volcado", rt: analysis.ServiceType, namespace: "demo", notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listNames(client, tt.rt, tt.namespace)
+			if apierrors.IsNotFound(err) != tt.notFound {
+				t.Fatalf("List %s en %q: error %v, se esperaba NotFound: %v", tt.rt.Kind, tt.namespace, err, tt.notFound)
+			}
+			if got != tt.want {
+				t.Errorf("List %s en %q = %q, se esperaba %q", tt.rt.Kind, tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOfflineClientEmpty(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{name: "directorio sin manifiestos", files: map[string]string{"README.md": "# volcado"}},
+		{name: "solo documentos que no son recursos", files: map[string]string{"kustomization.yaml": "resources: [a.yaml]\n---\n"}},
+		{name: "solo archivos que no se pueden decodificar", files: map[string]string{"svc.yaml": "metadata:\n  name: {{ .Release.Name }}\n  labels:\n    {{- include \"x\" . }}\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			_, _, err := NewOfflineClient([]string{dir})
+			if err == nil || !strings.Contains(err.Error(), "no contiene ningún recurso") {
+				t.Errorf("NewOfflineClient = %v, se esperaba el error de volcado vacío", err)
+			}
+		})
+	}
+}
+
+func TestNewOfflineClientSkipsUndecodableFiles(t *testing.T) {
+	helmTemplate := "apiVersion: v1\nkind: Service\nmetadata:\n  name: {{ .Release.Name }}\n  labels:\n    {{- include \"x\" . }}\n"
+	dir := writeFiles(t, map[string]string{
+		"dataplanes.yaml":        offlineDataplane,
+		"templates/service.yaml": helmTemplate,
+		"dump.tar.gz":            string(tarball(t, map[string]string{"broken.yaml": helmTemplate})),
+	})
+
+	client, warnings, err := NewOfflineClient([]string{dir})
+	if err != nil {
+		t.Fatalf("NewOfflineClient: %v", err)
+	}
+	if got, err := listNames(client, analysis.DataplaneType, ""); err != nil || got != "web-1" {
+		t.Errorf("Dataplanes = %q (%v), se esperaba web-1", got, err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("advertencias = %v, se esperaban 2", warnings)
+	}
+	for i, name := range []string{"dump.tar.gz:broken.yaml", filepath.Join("templates", "service.yaml")} {
+		if !strings.Contains(warnings[i], name) {
+			t.Errorf("la advertencia %q no nombra %s", warnings[i], name)
+		}
+	}
+}
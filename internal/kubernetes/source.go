@@ -0,0 +1,95 @@
+// internal/kubernetes/source.go
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"kuma-doctor/pkg/analysis"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// dynamicSource implementa analysis.Source sobre un cliente dinámico de Kubernetes,
+// ya sea de un clúster real o el cliente en memoria del modo offline.
+type dynamicSource struct {
+	client    dynamic.Interface
+	discovery discovery.DiscoveryInterface // nil en modo offline
+}
+
+// NewSource devuelve un analysis.Source que lee los CRDs de Kuma a través del cliente dinámico.
+// Si se indica un cliente de discovery, el Source implementa además analysis.Discoverer.
+func NewSource(client dynamic.Interface, discoveryClient discovery.DiscoveryInterface) analysis.Source {
+	return &dynamicSource{client: client, discovery: discoveryClient}
+}
+
+// Discover consulta qué versiones y recursos sirve el API server para un grupo.
+func (s *dynamicSource) Discover(ctx context.Context, group string) (*analysis.APIDiscovery, error) {
+	if s.discovery == nil {
+		return nil, analysis.ErrDiscoveryUnsupported
+	}
+	groups, err := s.discovery.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &analysis.APIDiscovery{Group: group, Resources: make(map[string]map[string]analysis.DiscoveredResource)}
+	for _, apiGroup := range groups.Groups {
+		if apiGroup.Name != group {
+			continue
+		}
+		result.PreferredVersion = apiGroup.PreferredVersion.Version
+		for _, version := range apiGroup.Versions {
+			list, err := s.discovery.ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				return nil, fmt.Errorf("error al consultar los recursos de %s: %w", version.GroupVersion, err)
+			}
+			resources := make(map[string]analysis.DiscoveredResource)
+			for _, resource := range list.APIResources {
+				if strings.Contains(resource.Name, "/") {
+					continue // Subrecursos (status, scale...)
+				}
+				resources[resource.Kind] = analysis.DiscoveredResource{Name: resource.Name, Namespaced: resource.Namespaced}
+			}
+			result.Resources[version.Version] = resources
+		}
+	}
+	return result, nil
+}
+
+// listPageSize limita el tamaño de cada página al listar; en clústeres con miles de
+// Dataplanes una única respuesta sin paginar es lenta y costosa para el API server.
+const listPageSize = 500
+
+// List recorre todas las páginas del recurso usando Limit/Continue.
+func (s *dynamicSource) List(ctx context.Context, rt analysis.ResourceType, namespace string) ([]unstructured.Unstructured, error) {
+	var resource dynamic.ResourceInterface = s.client.Resource(rt.GVR)
+	if rt.Namespaced {
+		resource = s.client.Resource(rt.GVR).Namespace(namespace)
+	}
+
+	var items []unstructured.Unstructured
+	opts := metav1.ListOptions{Limit: listPageSize}
+	for {
+		list, err := resource.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, list.Items...)
+		if list.GetContinue() == "" {
+			return items, nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+func (s *dynamicSource) Get(ctx context.Context, rt analysis.ResourceType, namespace, name string) (*unstructured.Unstructured, error) {
+	var resource dynamic.ResourceInterface = s.client.Resource(rt.GVR)
+	if rt.Namespaced {
+		resource = s.client.Resource(rt.GVR).Namespace(namespace)
+	}
+	return resource.Get(ctx, name, metav1.GetOptions{})
+}
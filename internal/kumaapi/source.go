@@ -0,0 +1,367 @@
+// internal/kumaapi/source.go
+package kumaapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"kuma-doctor/pkg/analysis"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// kumaGroup es el grupo de API de los recursos que sirve el control plane.
+const kumaGroup = "kuma.io"
+
+// DefaultTimeout es el tiempo máximo de cada petición a la API si Options no indica otro.
+const DefaultTimeout = 30 * time.Second
+
+// pageSize es el número de elementos que se pide por página a la API del control plane.
+const pageSize = 500
+
+// Options define la conexión con la API REST del control plane de Kuma (modo Universal).
+type Options struct {
+	URL                string // URL base de la API, p. ej. https://kuma-cp:5682
+	Token              string // Token de usuario (se envía como 'Authorization: Bearer').
+	TokenFile          string // Alternativa a Token para no exponerlo en la línea de comandos.
+	CACert             string // CA con la que validar el certificado del control plane.
+	ClientCert         string // Certificado de cliente para mTLS con la API (opcional).
+	ClientKey          string // Clave del certificado de cliente (opcional).
+	InsecureSkipVerify bool   // Desactiva la verificación TLS (solo para pruebas).
+	// Timeout es el tiempo máximo de cada petición; con 0 se usa DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Source implementa analysis.Source sobre la API REST del control plane de Kuma
+// (/meshes, /meshes/{mesh}/dataplanes, /meshes/{mesh}/meshtrafficpermissions, ...).
+type Source struct {
+	baseURL *url.URL
+	token   string
+	http    *http.Client
+
+	// Los recursos se listan por mesh, así que la lista de meshes se consulta una sola vez.
+	meshesOnce sync.Once
+	meshes     []string
+	meshesErr  error
+}
+
+// NewSource crea un Source para el control plane indicado en opts.
+func NewSource(opts Options) (*Source, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(opts.URL, "/"))
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("URL del control plane inválida: %q", opts.URL)
+	}
+
+	token := opts.Token
+	if opts.TokenFile != "" {
+		data, err := os.ReadFile(opts.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("error al leer el token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	tlsConfig, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Source{
+		baseURL: baseURL,
+		token:   token,
+		http: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// newTLSConfig construye la configuración TLS. InsecureSkipVerify solo se activa si el
+// usuario lo pide explícitamente con --cp-insecure-skip-verify.
+func newTLSConfig(opts Options) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("error al leer la CA del control plane: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("el archivo %s no contiene certificados PEM válidos", opts.CACert)
+		}
+		config.RootCAs = pool
+	}
+	if opts.ClientCert != "" || opts.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error al cargar el certificado de cliente: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// List devuelve los recursos del tipo indicado de todos los meshes. En Universal no hay
+// namespaces, así que namespace se ignora.
+func (s *Source) List(ctx context.Context, rt analysis.ResourceType, namespace string) ([]unstructured.Unstructured, error) {
+	if rt.GVR.Group != kumaGroup {
+		// En Universal no hay recursos de Kubernetes (Deployments, Pods...).
+		return nil, apierrors.NewNotFound(rt.GVR.GroupResource(), "")
+	}
+	if rt.Kind == analysis.MeshType.Kind {
+		return s.list(ctx, rt, "/meshes", "")
+	}
+
+	meshes, err := s.meshNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var items []unstructured.Unstructured
+	for _, mesh := range meshes {
+		meshItems, err := s.list(ctx, rt, resourcePath(rt, mesh), mesh)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, meshItems...)
+	}
+	return items, nil
+}
+
+// resourcePath devuelve el endpoint de listado de un tipo de recurso en un mesh. La API no
+// expone los DataplaneInsight por separado: vienen en la vista '_overview' de los Dataplanes.
+// Los ExternalService, anteriores a las políticas con targetRef, usan un nombre con guion.
+func resourcePath(rt analysis.ResourceType, mesh string) string {
+	switch rt.Kind {
+	case analysis.DataplaneInsightType.Kind:
+		return fmt.Sprintf("/meshes/%s/dataplanes/_overview", url.PathEscape(mesh))
+	case analysis.ExternalServiceType.Kind:
+		return fmt.Sprintf("/meshes/%s/external-services", url.PathEscape(mesh))
+	}
+	return fmt.Sprintf("/meshes/%s/%s", url.PathEscape(mesh), rt.GVR.Resource)
+}
+
+func (s *Source) meshNames(ctx context.Context) ([]string, error) {
+	s.meshesOnce.Do(func() {
+		meshes, err := s.list(ctx, analysis.MeshType, "/meshes", "")
+		if err != nil {
+			s.meshesErr = err
+			return
+		}
+		for _, mesh := range meshes {
+			s.meshes = append(s.meshes, mesh.GetName())
+		}
+	})
+	return s.meshes, s.meshesErr
+}
+
+// Get devuelve un recurso por nombre. Para los recursos que pertenecen a un mesh se busca
+// en todos los meshes, ya que analysis.Source no conoce el mesh del recurso.
+func (s *Source) Get(ctx context.Context, rt analysis.ResourceType, namespace, name string) (*unstructured.Unstructured, error) {
+	if rt.GVR.Group != kumaGroup {
+		return nil, apierrors.NewNotFound(rt.GVR.GroupResource(), name)
+	}
+	if rt.Kind == analysis.MeshType.Kind {
+		var item map[string]interface{}
+		if err := s.get(ctx, "/meshes/"+url.PathEscape(name), rt, &item); err != nil {
+			return nil, err
+		}
+		obj := toUnstructured(rt, item, "")
+		return &obj, nil
+	}
+
+	items, err := s.List(ctx, rt, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].GetName() == name {
+			return &items[i], nil
+		}
+	}
+	return nil, apierrors.NewNotFound(rt.GVR.GroupResource(), name)
+}
+
+// indexResponse es la respuesta de la raíz de la API del control plane.
+type indexResponse struct {
+	Version string `json:"version"`
+	// BasedOnKuma es la versión de Kuma en las distribuciones basadas en ella (Kong Mesh),
+	// cuyo 'version' es el de la distribución.
+	BasedOnKuma string `json:"basedOnKuma"`
+}
+
+// ControlPlaneVersion devuelve la versión de Kuma del control plane según la raíz de su API.
+func (s *Source) ControlPlaneVersion(ctx context.Context) (string, error) {
+	var index indexResponse
+	if err := s.get(ctx, "/", analysis.ResourceType{}, &index); err != nil {
+		return "", err
+	}
+	if index.BasedOnKuma != "" {
+		return index.BasedOnKuma, nil
+	}
+	return index.Version, nil
+}
+
+// listPage es el formato de las respuestas paginadas de la API de Kuma.
+type listPage struct {
+	Items []map[string]interface{} `json:"items"`
+	Next  *string                  `json:"next"`
+}
+
+// list recorre todas las páginas de un endpoint de listado siguiendo el campo 'next'.
+func (s *Source) list(ctx context.Context, rt analysis.ResourceType, path, mesh string) ([]unstructured.Unstructured, error) {
+	var items []unstructured.Unstructured
+	next := fmt.Sprintf("%s?size=%d", path, pageSize)
+	for next != "" {
+		var page listPage
+		if err := s.get(ctx, next, rt, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			items = append(items, toUnstructured(rt, item, mesh))
+		}
+		next = ""
+		if page.Next != nil && *page.Next != "" {
+			next = *page.Next
+		}
+	}
+	return items, nil
+}
+
+// get hace una petición GET y decodifica el JSON de la respuesta. ref puede ser una ruta
+// relativa a la URL base o una URL absoluta (el campo 'next' de la paginación).
+func (s *Source) get(ctx context.Context, ref string, rt analysis.ResourceType, out interface{}) error {
+	target, err := s.resolve(ref)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("error al consultar el control plane: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error al leer la respuesta de %s: %w", target, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		// Igual que un CRD no instalado en Kubernetes: el tipo no existe en este control plane.
+		return apierrors.NewNotFound(rt.GVR.GroupResource(), "")
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("el control plane rechazó la petición a %s (%s): revisa el token", target, resp.Status)
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("el control plane respondió %s a %s: %s", resp.Status, target, strings.TrimSpace(string(body)))
+	}
+
+	// utiljson conserva los enteros como int64, igual que el cliente de Kubernetes.
+	if err := utiljson.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("respuesta inválida de %s: %w", target, err)
+	}
+	return nil
+}
+
+// resolve convierte ref en una URL absoluta. Las URL absolutas solo se aceptan si apuntan al
+// mismo esquema y host que --cp-url: el token va en todas las peticiones y no debe llegar a
+// otro servidor aunque una respuesta lo enlace.
+func (s *Source) resolve(ref string) (string, error) {
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if parsed.IsAbs() {
+		if !strings.EqualFold(parsed.Scheme, s.baseURL.Scheme) || !strings.EqualFold(parsed.Host, s.baseURL.Host) {
+			return "", fmt.Errorf("el control plane enlazó una página en otro servidor (%s://%s) y no se sigue para no enviarle el token", parsed.Scheme, parsed.Host)
+		}
+		return parsed.String(), nil
+	}
+	base := *s.baseURL
+	base.Path = strings.TrimSuffix(base.Path, "/") + parsed.Path
+	base.RawQuery = parsed.RawQuery
+	return base.String(), nil
+}
+
+// toUnstructured convierte un recurso de la API de Kuma a la forma de su CRD de Kubernetes.
+// Las políticas nuevas (MeshTrafficPermission, MeshRetry...) ya tienen un campo 'spec'; los
+// recursos clásicos (Mesh, Dataplane) llevan la especificación en el primer nivel, que es
+// lo que el CRD de Kubernetes guarda bajo 'spec'.
+func toUnstructured(rt analysis.ResourceType, item map[string]interface{}, mesh string) unstructured.Unstructured {
+	name, _ := item["name"].(string)
+	if itemMesh, ok := item["mesh"].(string); ok && itemMesh != "" {
+		mesh = itemMesh
+	}
+
+	labels := make(map[string]interface{})
+	if itemLabels, ok := item["labels"].(map[string]interface{}); ok {
+		for k, v := range itemLabels {
+			labels[k] = v
+		}
+	}
+	metadata := map[string]interface{}{"name": name}
+
+	obj := map[string]interface{}{
+		"apiVersion": rt.GVR.GroupVersion().String(),
+		"kind":       rt.Kind,
+		"metadata":   metadata,
+	}
+	switch spec, hasSpec := item["spec"].(map[string]interface{}); {
+	case rt.Kind == analysis.DataplaneInsightType.Kind:
+		// Un elemento de '_overview' trae el Dataplane y su insight; el CRD guarda el
+		// insight bajo 'status'.
+		insight, _ := item["dataplaneInsight"].(map[string]interface{})
+		if insight == nil {
+			insight = map[string]interface{}{}
+		}
+		obj["status"] = insight
+	case hasSpec:
+		obj["spec"] = spec
+	default:
+		spec = make(map[string]interface{})
+		for k, v := range item {
+			switch k {
+			case "type", "name", "mesh", "labels", "creationTime", "modificationTime":
+			default:
+				spec[k] = v
+			}
+		}
+		obj["spec"] = spec
+	}
+
+	if rt.Kind != analysis.MeshType.Kind {
+		labels["kuma.io/mesh"] = mesh
+		obj["mesh"] = mesh
+	}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	return unstructured.Unstructured{Object: obj}
+}
+
+// Asegura en tiempo de compilación que Source cumple las interfaces.
+var (
+	_ analysis.Source                = (*Source)(nil)
+	_ analysis.ControlPlaneVersioner = (*Source)(nil)
+)
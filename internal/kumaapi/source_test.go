@@ -0,0 +1,255 @@
+// internal/kumaapi/source_test.go
+package kumaapi
+
+import (
+	"context"
+	"encoding/json"
+	"kuma-doctor/pkg/analysis"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const testToken = "secret"
+
+// newTestSource arranca un control plane de prueba que sirve routes (ruta con query -> cuerpo
+// JSON) y exige el token en todas las peticiones. En los cuerpos, {{URL}} se sustituye por la
+// URL del servidor, para poder devolver enlaces 'next' absolutos como la API real.
+func newTestSource(t *testing.T, routes map[string]string) *Source {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer "+testToken {
+			t.Errorf("%s: Authorization = %q, se esperaba el token como Bearer", r.URL, got)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(strings.ReplaceAll(body, "{{URL}}", server.URL)))
+	}))
+	t.Cleanup(server.Close)
+
+	source, err := NewSource(Options{URL: server.URL, Token: testToken})
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	return source
+}
+
+const testMeshes = `{"total": 1, "items": [{"type": "Mesh", "name": "default", "mtls": {"enabledBackend": "ca-1"}}], "next": null}`
+
+func TestListFollowsNext(t *testing.T) {
+	source := newTestSource(t, map[string]string{
+		"/meshes?size=500": testMeshes,
+		"/meshes/default/dataplanes?size=500": `{"total": 2, "items": [{"type": "Dataplane", "mesh": "default", "name": "web-1"}],
+			"next": "{{URL}}/meshes/default/dataplanes?offset=1&size=500"}`,
+		"/meshes/default/dataplanes?offset=1&size=500": `{"total": 2, "items": [{"type": "Dataplane", "mesh": "default", "name": "api-1"}], "next": null}`,
+	})
+
+	items, err := source.List(context.Background(), analysis.DataplaneType, "")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item.GetName())
+	}
+	if got := strings.Join(names, ","); got != "web-1,api-1" {
+		t.Errorf("Dataplanes = %s, se esperaban las dos páginas (web-1,api-1)", got)
+	}
+}
+
+func TestListRejectsNextOnAnotherHost(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("se siguió el enlace 'next' a otro servidor (Authorization = %q)", r.Header.Get("Authorization"))
+	}))
+	defer other.Close()
+	source := newTestSource(t, map[string]string{
+		"/meshes?size=500": testMeshes,
+		"/meshes/default/dataplanes?size=500": `{"total": 2, "items": [{"type": "Dataplane", "mesh": "default", "name": "web-1"}],
+			"next": "` + other.URL + `/meshes/default/dataplanes?offset=1&size=500"}`,
+	})
+
+	_, err := source.List(context.Background(), analysis.DataplaneType, "")
+	if err == nil || !strings.Contains(err.Error(), "otro servidor") {
+		t.Errorf("List = %v, se esperaba el error del enlace a otro servidor", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	source, err := NewSource(Options{URL: "https://kuma-cp:5682/api/"})
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{ref: "/meshes?size=500", want: "https://kuma-cp:5682/api/meshes?size=500"},
+		{ref: "https://kuma-cp:5682/api/meshes?offset=500&size=500", want: "https://kuma-cp:5682/api/meshes?offset=500&size=500"},
+		{ref: "HTTPS://KUMA-CP:5682/api/meshes?offset=500", want: "https://KUMA-CP:5682/api/meshes?offset=500"},
+		{ref: "http://kuma-cp:5682/api/meshes?offset=500", wantErr: true},
+		{ref: "https://kuma-cp:5681/api/meshes?offset=500", wantErr: true},
+		{ref: "https://attacker.example/meshes?offset=500", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := source.resolve(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve(%q) error = %v, se esperaba error: %v", tt.ref, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolve(%q) = %q, se esperaba %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		notFound bool
+	}{
+		{name: "401", status: http.StatusUnauthorized},
+		{name: "403", status: http.StatusForbidden},
+		{name: "404", status: http.StatusNotFound, notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			source, err := NewSource(Options{URL: server.URL, Token: testToken})
+			if err != nil {
+				t.Fatalf("NewSource: %v", err)
+			}
+
+			_, err = source.List(context.Background(), analysis.MeshType, "")
+			switch {
+			case err == nil:
+				t.Fatalf("List no devolvió error con %d", tt.status)
+			case apierrors.IsNotFound(err) != tt.notFound:
+				t.Errorf("IsNotFound(%v) = %v, se esperaba %v", err, apierrors.IsNotFound(err), tt.notFound)
+			case !tt.notFound && !strings.Contains(err.Error(), "revisa el token"):
+				t.Errorf("el error %q no indica que se revise el token", err)
+			}
+		})
+	}
+}
+
+func TestDataplaneInsightFromOverview(t *testing.T) {
+	source := newTestSource(t, map[string]string{
+		"/meshes?size=500": testMeshes,
+		"/meshes/default/dataplanes/_overview?size=500": `{"total": 1, "items": [{"type": "DataplaneOverview", "mesh": "default", "name": "web-1",
+			"dataplane": {"networking": {"address": "10.0.0.1"}},
+			"dataplaneInsight": {"subscriptions": [{"id": "1", "controlPlaneInstanceId": "cp-a"}]}}], "next": null}`,
+	})
+
+	items, err := source.List(context.Background(), analysis.DataplaneInsightType, "")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("se obtuvieron %d DataplaneInsights, se esperaba 1", len(items))
+	}
+	insight := items[0]
+	if insight.GetName() != "web-1" || insight.GetKind() != "DataplaneInsight" {
+		t.Errorf("insight = %s %s, se esperaba DataplaneInsight web-1", insight.GetKind(), insight.GetName())
+	}
+	subscriptions, _, _ := unstructured.NestedSlice(insight.Object, "status", "subscriptions")
+	if len(subscriptions) != 1 {
+		t.Errorf("status.subscriptions = %v, se esperaba la suscripción del dataplaneInsight", subscriptions)
+	}
+	if _, found := insight.Object["spec"]; found {
+		t.Errorf("el insight no debería tener spec: %v", insight.Object)
+	}
+}
+
+func TestToUnstructured(t *testing.T) {
+	tests := []struct {
+		name string
+		rt   analysis.ResourceType
+		item string
+		want string // JSON esperado de 'spec'
+		mesh string // etiqueta kuma.io/mesh esperada
+	}{
+		{
+			name: "Mesh plano",
+			rt:   analysis.MeshType,
+			item: `{"type": "Mesh", "name": "default", "creationTime": "2026-01-01T00:00:00Z", "mtls": {"enabledBackend": "ca-1"}}`,
+			want: `{"mtls":{"enabledBackend":"ca-1"}}`,
+		},
+		{
+			name: "Dataplane plano",
+			rt:   analysis.DataplaneType,
+			item: `{"type": "Dataplane", "mesh": "demo", "name": "web-1", "labels": {"team": "a"}, "networking": {"address": "10.0.0.1"}}`,
+			want: `{"networking":{"address":"10.0.0.1"}}`,
+			mesh: "demo",
+		},
+		{
+			name: "política con spec",
+			rt:   analysis.MeshTrafficPermissionType,
+			item: `{"type": "MeshTrafficPermission", "mesh": "demo", "name": "allow", "spec": {"targetRef": {"kind": "Mesh"}}}`,
+			want: `{"targetRef":{"kind":"Mesh"}}`,
+			mesh: "demo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var item map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.item), &item); err != nil {
+				t.Fatal(err)
+			}
+			obj := toUnstructured(tt.rt, item, "")
+
+			spec, err := json.Marshal(obj.Object["spec"])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(spec) != tt.want {
+				t.Errorf("spec = %s, se esperaba %s", spec, tt.want)
+			}
+			if obj.GetKind() != tt.rt.Kind || obj.GetName() != item["name"] {
+				t.Errorf("objeto = %s %s, se esperaba %s %s", obj.GetKind(), obj.GetName(), tt.rt.Kind, item["name"])
+			}
+			if got := obj.GetLabels()["kuma.io/mesh"]; got != tt.mesh {
+				t.Errorf("kuma.io/mesh = %q, se esperaba %q", got, tt.mesh)
+			}
+		})
+	}
+}
+
+func TestControlPlaneVersion(t *testing.T) {
+	tests := []struct {
+		name  string
+		index string
+		want  string
+	}{
+		{name: "Kuma", index: `{"tagline": "Kuma", "version": "2.9.3"}`, want: "2.9.3"},
+		{name: "basada en Kuma", index: `{"tagline": "Kong Mesh", "version": "2.9.1", "basedOnKuma": "2.9.3"}`, want: "2.9.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newTestSource(t, map[string]string{"/": tt.index})
+			got, err := source.ControlPlaneVersion(context.Background())
+			if err != nil {
+				t.Fatalf("ControlPlaneVersion: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ControlPlaneVersion = %s, se esperaba %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// internal/report/explain.go
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"kuma-doctor/pkg/analysis"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// GenerateExplanation renderiza la configuración efectiva de un Dataplane en el formato
+// indicado (txt, md o json).
+func GenerateExplanation(format string, explanation *analysis.DataplaneExplanation) (string, error) {
+	switch format {
+	case "txt":
+		return explanationText(explanation)
+	case "md":
+		return explanationMarkdown(explanation)
+	case "json":
+		bytes, err := json.MarshalIndent(explanation, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	default:
+		return "", fmt.Errorf("formato de reporte desconocido: %s", format)
+	}
+}
+
+func explanationText(explanation *analysis.DataplaneExplanation) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(bold(fmt.Sprintf("--- Políticas efectivas de %s (mesh %s) ---\n", explanation.Dataplane, explanation.Dataplane.Mesh)))
+	sb.WriteString(fmt.Sprintf("Fecha: %s\n", explanation.GeneratedAt.Format(time.RFC1123)))
+	sb.WriteString(fmt.Sprintf("Tipo: %s\n", proxyKind(explanation)))
+	sb.WriteString(fmt.Sprintf("Servicios: %s\n", joinOrNone(explanation.Services)))
+
+	for _, policy := range explanation.Policies {
+		sb.WriteString(bold(fmt.Sprintf("\n== %s ==\n", policy.Kind)))
+		switch {
+		case !policy.Installed:
+			sb.WriteString(yellow("⏭️ El CRD no está instalado.\n"))
+			continue
+		case len(policy.Matches) == 0:
+			sb.WriteString("Ninguna política se aplica a este Dataplane.\n")
+			continue
+		}
+
+		sb.WriteString("Orden de fusión (cada política sobrescribe a las anteriores):\n")
+		w := tabwriter.NewWriter(&sb, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, bold("  #\tPOLÍTICA\tTARGETREF\tSERVICIOS"))
+		for i, match := range policy.Matches {
+			fmt.Fprintf(w, "  %d\t%s\t%s\t%s\n", i+1, match.Policy, match.TargetRef, joinOrNone(match.Services))
+		}
+		w.Flush()
+
+		sb.WriteString("Configuración efectiva:\n")
+		for _, effective := range policy.Effective {
+			conf, err := yaml.Marshal(effective.Conf)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(cyan(fmt.Sprintf("  %s\n", effectiveHeading(effective))))
+			sb.WriteString(fmt.Sprintf("    (de %s)\n", strings.Join(effective.Origins, " → ")))
+			sb.WriteString(indent(string(conf), "    "))
+		}
+	}
+
+	writeExplanationDiagnostics(&sb, explanation, yellow("\nAdvertencias del análisis (resultado incompleto):\n"), "  - ")
+	return sb.String(), nil
+}
+
+func explanationMarkdown(explanation *analysis.DataplaneExplanation) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Políticas efectivas de `%s`\n\n", explanation.Dataplane))
+	sb.WriteString(fmt.Sprintf("**Fecha:** %s\n\n", explanation.GeneratedAt.Format(time.RFC1123)))
+	sb.WriteString(fmt.Sprintf("- **Mesh:** %s\n", explanation.Dataplane.Mesh))
+	sb.WriteString(fmt.Sprintf("- **Tipo:** %s\n", proxyKind(explanation)))
+	sb.WriteString(fmt.Sprintf("- **Servicios:** %s\n", joinOrNone(explanation.Services)))
+
+	for _, policy := range explanation.Policies {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", policy.Kind))
+		switch {
+		case !policy.Installed:
+			sb.WriteString("> ⏭️ El CRD no está instalado.\n")
+			continue
+		case len(policy.Matches) == 0:
+			sb.WriteString("Ninguna política se aplica a este Dataplane.\n")
+			continue
+		}
+
+		sb.WriteString("| # | Política | TargetRef | Servicios |\n")
+		sb.WriteString("|---|---|---|---|\n")
+		for i, match := range policy.Matches {
+			sb.WriteString(fmt.Sprintf("| %d | `%s` | `%s` | %s |\n", i+1, match.Policy, match.TargetRef, joinOrNone(match.Services)))
+		}
+
+		for _, effective := range policy.Effective {
+			conf, err := yaml.Marshal(effective.Conf)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(fmt.Sprintf("\n**%s** (de %s)\n\n", effectiveHeading(effective), strings.Join(effective.Origins, " → ")))
+			sb.WriteString("```yaml\n" + string(conf) + "```\n")
+		}
+	}
+
+	writeExplanationDiagnostics(&sb, explanation, "\n**Advertencias del análisis (resultado incompleto):**\n\n", "- ⚠️ ")
+	return sb.String(), nil
+}
+
+// effectiveHeading describe a qué se aplica una configuración efectiva (p. ej. "to MeshService/backend").
+func effectiveHeading(effective analysis.EffectiveConf) string {
+	if effective.TargetRef == nil {
+		return effective.Section
+	}
+	return fmt.Sprintf("%s %s", effective.Section, effective.TargetRef)
+}
+
+func writeExplanationDiagnostics(sb *strings.Builder, explanation *analysis.DataplaneExplanation, heading, bullet string) {
+	if len(explanation.Diagnostics) == 0 {
+		return
+	}
+	sb.WriteString(heading)
+	for _, diagnostic := range explanation.Diagnostics {
+		sb.WriteString(bullet + diagnostic + "\n")
+	}
+}
+
+func proxyKind(explanation *analysis.DataplaneExplanation) string {
+	if explanation.Gateway {
+		return "gateway"
+	}
+	return "sidecar"
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, ", ")
+}
+
+func indent(text, prefix string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
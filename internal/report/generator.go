@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"kuma-doctor/pkg/analysis"
+	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -48,74 +49,107 @@ func (r *TextReporter) Generate(results []*analysis.ValidationResult) (string, e
 	var finalReport strings.Builder
 	for i, result := range results {
 		var sb strings.Builder
+		if startsMeshGroup(results, i) {
+			sb.WriteString(bold(fmt.Sprintf("===== Mesh: %s =====\n\n", result.Mesh)))
+		}
 		sb.WriteString(bold(fmt.Sprintf("--- %s ---\n", result.Title)))
 		sb.WriteString(fmt.Sprintf("Fecha: %s\n\n", result.GeneratedAt.Format(time.RFC1123)))
 
-		if len(result.Findings) == 0 {
+		w := tabwriter.NewWriter(&sb, 0, 0, 3, ' ', 0)
+		switch {
+		case result.Status == analysis.StatusError:
+			sb.WriteString(red(fmt.Sprintf("❌ El análisis falló: %s\n", result.Reason)))
+		case result.Status == analysis.StatusSkipped:
+			sb.WriteString(yellow(fmt.Sprintf("⏭️ Análisis omitido: %s\n", result.Reason)))
+		case result.Summary != nil:
+			summary := result.Summary
+			fmt.Fprintln(w, bold("RECURSO\tCANTIDAD\t"))
+			fmt.Fprintln(w, bold("-------\t--------\t"))
+			fmt.Fprintf(w, "%s\t%d\t\n", "Meshes", summary.TotalMeshes)
+			fmt.Fprintln(w, "\t\t")
+			fmt.Fprintf(w, "%s\t%d\t\n", "Dataplanes Totales", summary.TotalDataplanes)
+			fmt.Fprintf(w, "  %s\t%d\t\n", green("✅ En Línea"), summary.OnlineDataplanes)
+			fmt.Fprintf(w, "  %s\t%d\t\n", red("❌ Fuera de Línea"), summary.OfflineDataplanes)
+			fmt.Fprintf(w, "  %s\t%d\t\n", yellow("⚠️ Degradados"), summary.DegradedDataplanes)
+			fmt.Fprintf(w, "  %s\t%d\t\n", cyan("ℹ️ Informativos"), summary.InfoDataplanes)
+			fmt.Fprintf(w, "  %s\t%d\t\n", yellow("💤 Obsoletos"), summary.StaleDataplanes)
+			fmt.Fprintln(w, "\t\t")
+			fmt.Fprintf(w, "%s\t%d\t\n", "Políticas de Tráfico (MTPs)", summary.TotalPolicies)
+		case len(result.Findings) == 0:
 			sb.WriteString(green("✅ No se encontraron hallazgos problemáticos.\n"))
-		} else {
-			w := tabwriter.NewWriter(&sb, 0, 0, 3, ' ', 0)
-			switch result.Findings[0].(type) {
-			case analysis.DataplaneStatus:
-				fmt.Fprintln(w, bold("NOMBRE\tNAMESPACE\tESTADO\tDETALLES"))
-				fmt.Fprintln(w, bold("------\t---------\t------\t--------"))
-				for _, finding := range result.Findings {
-					dpStatus, _ := finding.(analysis.DataplaneStatus)
-					var statusCell string
-					switch dpStatus.Status {
-					case "Online":
-						statusCell = green("✅ Online")
-					case "Offline":
-						statusCell = red("❌ Offline")
-					case "Degraded":
-						statusCell = yellow("⚠️ Degraded")
-					case "Info":
-						statusCell = cyan("ℹ️ Info")
+		case isDataplaneResult(result):
+			fmt.Fprintln(w, bold("NOMBRE\tNAMESPACE\tESTADO\tDETALLES"))
+			fmt.Fprintln(w, bold("------\t---------\t------\t--------"))
+			for _, finding := range result.Findings {
+				dpStatus := finding.Dataplane
+				var statusCell string
+				switch dpStatus.Status {
+				case "Online":
+					statusCell = green("✅ Online")
+				case "Offline":
+					statusCell = red("❌ Offline")
+				case "Degraded":
+					statusCell = yellow("⚠️ Degraded")
+				case "Info":
+					statusCell = cyan("ℹ️ Info")
+				case "Stale":
+					statusCell = yellow("💤 Stale")
+				default:
+					// Estados derivados del DataplaneInsight (Disconnected, CertExpiring...).
+					switch finding.Severity {
+					case analysis.SeverityAlert:
+						statusCell = red("🚨 " + dpStatus.Status)
+					case analysis.SeverityWarn:
+						statusCell = yellow("⚠️ " + dpStatus.Status)
+					default:
+						statusCell = cyan("ℹ️ " + dpStatus.Status)
 					}
-					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", dpStatus.Name, dpStatus.Namespace, statusCell, dpStatus.Details)
 				}
-			case analysis.SummaryStatus:
-				summary := result.Findings[0].(analysis.SummaryStatus)
-				fmt.Fprintln(w, bold("RECURSO\tCANTIDAD\t"))
-				fmt.Fprintln(w, bold("-------\t--------\t"))
-				fmt.Fprintf(w, "%s\t%d\t\n", "Meshes", summary.TotalMeshes)
-				fmt.Fprintln(w, "\t\t")
-				fmt.Fprintf(w, "%s\t%d\t\n", "Dataplanes Totales", summary.TotalDataplanes)
-				fmt.Fprintf(w, "  %s\t%d\t\n", green("✅ En Línea"), summary.OnlineDataplanes)
-				fmt.Fprintf(w, "  %s\t%d\t\n", red("❌ Fuera de Línea"), summary.OfflineDataplanes)
-				fmt.Fprintf(w, "  %s\t%d\t\n", yellow("⚠️ Degradados"), summary.DegradedDataplanes)
-				fmt.Fprintf(w, "  %s\t%d\t\n", cyan("ℹ️ Informativos"), summary.InfoDataplanes)
-				fmt.Fprintln(w, "\t\t")
-				fmt.Fprintf(w, "%s\t%d\t\n", "Políticas de Tráfico (MTPs)", summary.TotalPolicies)
-			case analysis.PolicyFinding, analysis.MTLSFinding, analysis.ResilienceFinding, analysis.ObservabilityFinding:
-				fmt.Fprintln(w, bold("NIVEL\tRECURSO/TIPO\tMENSAJE"))
-				fmt.Fprintln(w, bold("-----\t------------\t-------"))
-				for _, finding := range result.Findings {
-					var level, resource, message string
-					switch f := finding.(type) {
-					case analysis.PolicyFinding:
-						level, resource, message = f.Level, f.Resource, f.Message
-					case analysis.MTLSFinding:
-						level, resource, message = f.Level, f.Resource, f.Message
-					case analysis.ResilienceFinding:
-						level, resource, message = f.Level, f.Service, fmt.Sprintf("(%s) %s", f.PolicyType, f.Message)
-					case analysis.ObservabilityFinding:
-						level, resource, message = f.Level, f.Resource, fmt.Sprintf("(%s) %s", f.PolicyType, f.Message)
-					}
-					var levelCell string
-					switch level {
-					case "ALERT":
-						levelCell = red("🚨 ALERT")
-					case "WARN":
-						levelCell = yellow("⚠️ WARN")
-					case "INFO":
-						levelCell = green("✅ INFO")
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", dpStatus.Name, dpStatus.Namespace, statusCell, dpStatus.Details)
+				for _, row := range endpointRows(dpStatus) {
+					var healthCell string
+					if row.ready != nil {
+						healthCell = green("✅ ready")
+						if !*row.ready {
+							healthCell = red("❌ no ready")
+						}
 					}
-					fmt.Fprintf(w, "%s\t%s\t%s\n", levelCell, resource, message)
+					fmt.Fprintf(w, "  %s\t\t%s\t%s\n", row.label, healthCell, row.detail)
+				}
+			}
+		default:
+			fmt.Fprintln(w, bold("NIVEL\tREGLA\tRECURSO\tMENSAJE"))
+			fmt.Fprintln(w, bold("-----\t-----\t-------\t-------"))
+			for _, finding := range result.Findings {
+				var levelCell string
+				switch finding.Severity {
+				case analysis.SeverityAlert:
+					levelCell = red("🚨 ALERT")
+				case analysis.SeverityWarn:
+					levelCell = yellow("⚠️ WARN")
+				case analysis.SeverityInfo:
+					levelCell = green("✅ INFO")
 				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", levelCell, finding.RuleID, finding.Resource, finding.Message)
+			}
+		}
+		w.Flush()
+
+		// Las remediaciones se listan una vez por regla para no repetirlas en cada fila.
+		if remediations := remediationsByRule(result); len(remediations) > 0 {
+			sb.WriteString(bold("\nRemediación:\n"))
+			for _, rem := range remediations {
+				sb.WriteString(fmt.Sprintf("  %s: %s\n", rem.ruleID, rem.text))
 			}
-			w.Flush()
 		}
+
+		if len(result.Diagnostics) > 0 {
+			sb.WriteString(yellow("\nAdvertencias del análisis (resultado incompleto):\n"))
+			for _, diagnostic := range result.Diagnostics {
+				sb.WriteString(fmt.Sprintf("  - %s\n", diagnostic))
+			}
+		}
+
 		finalReport.WriteString(sb.String())
 		if i < len(results)-1 {
 			finalReport.WriteString("\n\n") // Añade un separador entre reportes
@@ -127,13 +161,11 @@ func (r *TextReporter) Generate(results []*analysis.ValidationResult) (string, e
 // --- Implementación de JsonReporter ---
 type JsonReporter struct{}
 
+// Generate devuelve siempre un array de resultados, aunque solo haya uno, para que el esquema
+// no dependa del número de meshes o de análisis.
 func (r *JsonReporter) Generate(results []*analysis.ValidationResult) (string, error) {
-	if len(results) == 1 {
-		bytes, err := json.MarshalIndent(results[0], "", "  ")
-		if err != nil {
-			return "", err
-		}
-		return string(bytes), nil
+	if results == nil {
+		results = []*analysis.ValidationResult{}
 	}
 	bytes, err := json.MarshalIndent(results, "", "  ")
 	if err != nil {
@@ -149,65 +181,87 @@ func (r *MarkdownReporter) Generate(results []*analysis.ValidationResult) (strin
 	var finalReport strings.Builder
 	for i, result := range results {
 		var sb strings.Builder
+		if startsMeshGroup(results, i) {
+			sb.WriteString(fmt.Sprintf("# Mesh: %s\n\n", result.Mesh))
+		}
 		sb.WriteString(fmt.Sprintf("## %s\n\n", result.Title))
 		sb.WriteString(fmt.Sprintf("**Fecha:** %s\n\n", result.GeneratedAt.Format(time.RFC1123)))
-		if len(result.Findings) == 0 {
+		switch {
+		case result.Status == analysis.StatusError:
+			sb.WriteString(fmt.Sprintf("> ❌ **El análisis falló:** %s\n", result.Reason))
+		case result.Status == analysis.StatusSkipped:
+			sb.WriteString(fmt.Sprintf("> ⏭️ **Análisis omitido:** %s\n", result.Reason))
+		case result.Summary != nil:
+			summary := result.Summary
+			sb.WriteString(fmt.Sprintf("- **Meshes:** %d\n", summary.TotalMeshes))
+			sb.WriteString(fmt.Sprintf("- **Dataplanes Totales:** %d\n", summary.TotalDataplanes))
+			sb.WriteString(fmt.Sprintf("  - ✅ **En Línea:** %d\n", summary.OnlineDataplanes))
+			sb.WriteString(fmt.Sprintf("  - ❌ **Fuera de Línea:** %d\n", summary.OfflineDataplanes))
+			sb.WriteString(fmt.Sprintf("  - ⚠️ **Degradados:** %d\n", summary.DegradedDataplanes))
+			sb.WriteString(fmt.Sprintf("  - ℹ️ **Informativos:** %d\n", summary.InfoDataplanes))
+			sb.WriteString(fmt.Sprintf("  - 💤 **Obsoletos:** %d\n", summary.StaleDataplanes))
+			sb.WriteString(fmt.Sprintf("- **Políticas de Tráfico (MTPs):** %d\n", summary.TotalPolicies))
+		case len(result.Findings) == 0:
 			sb.WriteString("✅ No se encontraron hallazgos problemáticos.\n")
-		} else {
-			switch result.Findings[0].(type) {
-			case analysis.DataplaneStatus:
-				sb.WriteString("| Nombre | Namespace | Estado | Detalles |\n")
-				sb.WriteString("|---|---|---|---|\n")
-				for _, finding := range result.Findings {
-					dpStatus, _ := finding.(analysis.DataplaneStatus)
-					var emoji string
-					switch dpStatus.Status {
-					case "Online":
-						emoji = "✅"
-					case "Offline":
-						emoji = "❌"
-					case "Degraded":
+		case isDataplaneResult(result):
+			sb.WriteString("| Nombre | Namespace | Estado | Detalles |\n")
+			sb.WriteString("|---|---|---|---|\n")
+			for _, finding := range result.Findings {
+				dpStatus := finding.Dataplane
+				var emoji string
+				switch dpStatus.Status {
+				case "Online":
+					emoji = "✅"
+				case "Offline":
+					emoji = "❌"
+				case "Degraded":
+					emoji = "⚠️"
+				case "Info":
+					emoji = "ℹ️"
+				case "Stale":
+					emoji = "💤"
+				default:
+					switch finding.Severity {
+					case analysis.SeverityAlert:
+						emoji = "🚨"
+					case analysis.SeverityWarn:
 						emoji = "⚠️"
-					case "Info":
+					default:
 						emoji = "ℹ️"
 					}
-					sb.WriteString(fmt.Sprintf("| %s | %s | %s %s | %s |\n", dpStatus.Name, dpStatus.Namespace, emoji, dpStatus.Status, dpStatus.Details))
 				}
-			case analysis.SummaryStatus:
-				summary := result.Findings[0].(analysis.SummaryStatus)
-				sb.WriteString(fmt.Sprintf("- **Meshes:** %d\n", summary.TotalMeshes))
-				sb.WriteString(fmt.Sprintf("- **Dataplanes Totales:** %d\n", summary.TotalDataplanes))
-				sb.WriteString(fmt.Sprintf("  - ✅ **En Línea:** %d\n", summary.OnlineDataplanes))
-				sb.WriteString(fmt.Sprintf("  - ❌ **Fuera de Línea:** %d\n", summary.OfflineDataplanes))
-				sb.WriteString(fmt.Sprintf("  - ⚠️ **Degradados:** %d\n", summary.DegradedDataplanes))
-				sb.WriteString(fmt.Sprintf("  - ℹ️ **Informativos:** %d\n", summary.InfoDataplanes))
-				sb.WriteString(fmt.Sprintf("- **Políticas de Tráfico (MTPs):** %d\n", summary.TotalPolicies))
-
-			case analysis.PolicyFinding, analysis.MTLSFinding, analysis.ResilienceFinding, analysis.ObservabilityFinding:
-				sb.WriteString("| Nivel | Recurso/Tipo | Mensaje |\n")
-				sb.WriteString("|---|---|---|\n")
-				for _, finding := range result.Findings {
-					var level, resource, message, emoji string
-					switch f := finding.(type) {
-					case analysis.PolicyFinding:
-						level, resource, message = f.Level, f.Resource, f.Message
-					case analysis.MTLSFinding:
-						level, resource, message = f.Level, f.Resource, f.Message
-					case analysis.ResilienceFinding:
-						level, resource, message = f.Level, f.Service, fmt.Sprintf("_(%s)_ %s", f.PolicyType, f.Message)
-					case analysis.ObservabilityFinding:
-						level, resource, message = f.Level, f.Resource, fmt.Sprintf("_(%s)_ %s", f.PolicyType, f.Message)
-					}
-					switch level {
-					case "ALERT":
-						emoji = "🚨"
-					case "WARN":
-						emoji = "⚠️"
-					case "INFO":
-						emoji = "✅"
+				sb.WriteString(fmt.Sprintf("| %s | %s | %s %s | %s |\n", dpStatus.Name, dpStatus.Namespace, emoji, dpStatus.Status, dpStatus.Details))
+				for _, row := range endpointRows(dpStatus) {
+					var healthCell string
+					if row.ready != nil {
+						healthCell = "✅ ready"
+						if !*row.ready {
+							healthCell = "❌ no ready"
+						}
 					}
-					sb.WriteString(fmt.Sprintf("| %s %s | `%s` | %s |\n", emoji, level, resource, message))
+					sb.WriteString(fmt.Sprintf("| &nbsp;&nbsp;%s | | %s | %s |\n", row.label, healthCell, row.detail))
+				}
+			}
+		default:
+			sb.WriteString("| Nivel | Regla | Recurso | Mensaje | Remediación |\n")
+			sb.WriteString("|---|---|---|---|---|\n")
+			for _, finding := range result.Findings {
+				var emoji string
+				switch finding.Severity {
+				case analysis.SeverityAlert:
+					emoji = "🚨"
+				case analysis.SeverityWarn:
+					emoji = "⚠️"
+				case analysis.SeverityInfo:
+					emoji = "✅"
 				}
+				sb.WriteString(fmt.Sprintf("| %s %s | `%s` | `%s` | %s | %s |\n", emoji, finding.Severity, finding.RuleID, finding.Resource, finding.Message, finding.Remediation))
+			}
+		}
+		if len(result.Diagnostics) > 0 {
+			sb.WriteString("\n**Advertencias del análisis (resultado incompleto):**\n\n")
+			for _, diagnostic := range result.Diagnostics {
+				sb.WriteString(fmt.Sprintf("- ⚠️ %s\n", diagnostic))
 			}
 		}
 		finalReport.WriteString(sb.String())
@@ -217,3 +271,98 @@ func (r *MarkdownReporter) Generate(results []*analysis.ValidationResult) (strin
 	}
 	return finalReport.String(), nil
 }
+
+// --- Helpers compartidos por los reporters ---
+
+// startsMeshGroup indica si el resultado i es el primero de un mesh. Los runners devuelven
+// los resultados agrupados por mesh, así que basta con comparar con el anterior.
+func startsMeshGroup(results []*analysis.ValidationResult, i int) bool {
+	if results[i].Mesh == "" {
+		return false
+	}
+	return i == 0 || results[i-1].Mesh != results[i].Mesh
+}
+
+// isDataplaneResult indica si el resultado proviene del análisis de dataplanes,
+// que se muestra como una tabla de estados en lugar de una lista de hallazgos.
+func isDataplaneResult(result *analysis.ValidationResult) bool {
+	for _, finding := range result.Findings {
+		if finding.Dataplane == nil {
+			return false
+		}
+	}
+	return len(result.Findings) > 0
+}
+
+// maxOutboundRows limita los outbounds que se muestran bajo cada Dataplane en txt y md: con
+// proxy transparente el control plane declara uno por cada servicio alcanzable. El JSON los
+// incluye todos.
+const maxOutboundRows = 10
+
+// endpointRow es una fila anidada bajo un Dataplane con uno de sus inbounds u outbounds.
+type endpointRow struct {
+	label  string
+	ready  *bool // nil en los outbounds, que no tienen estado de salud
+	detail string
+}
+
+// endpointRows devuelve las filas de los inbounds y outbounds de un Dataplane.
+func endpointRows(dp *analysis.DataplaneStatus) []endpointRow {
+	var rows []endpointRow
+	for _, inbound := range dp.Inbounds {
+		ready := inbound.Ready
+		rows = append(rows, endpointRow{label: fmt.Sprintf("↳ inbound :%d", inbound.Port), ready: &ready, detail: formatTags(inbound.Tags)})
+	}
+	for i, outbound := range dp.Outbounds {
+		if i == maxOutboundRows {
+			rows = append(rows, endpointRow{label: fmt.Sprintf("↳ y %d outbounds más", len(dp.Outbounds)-maxOutboundRows)})
+			break
+		}
+		detail := formatTags(outbound.Tags)
+		if ref := outbound.BackendRef; ref != nil {
+			detail = fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+			if ref.Port != 0 {
+				detail += fmt.Sprintf(":%d", ref.Port)
+			}
+			if len(ref.Labels) > 0 {
+				detail += " " + formatTags(ref.Labels)
+			}
+		}
+		rows = append(rows, endpointRow{label: fmt.Sprintf("↳ outbound %s:%d", outbound.Address, outbound.Port), detail: detail})
+	}
+	return rows
+}
+
+// formatTags muestra unas etiquetas ordenadas por clave (k1=v1, k2=v2).
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+tags[key])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+type remediation struct {
+	ruleID string
+	text   string
+}
+
+// remediationsByRule devuelve las remediaciones de los hallazgos WARN/ALERT de un resultado,
+// una por regla y en el orden en que aparecen.
+func remediationsByRule(result *analysis.ValidationResult) []remediation {
+	var remediations []remediation
+	seen := make(map[string]bool)
+	for _, finding := range result.Findings {
+		if finding.Severity == analysis.SeverityInfo || finding.Remediation == "" || seen[finding.RuleID] {
+			continue
+		}
+		seen[finding.RuleID] = true
+		remediations = append(remediations, remediation{ruleID: finding.RuleID, text: finding.Remediation})
+	}
+	return remediations
+}
@@ -0,0 +1,211 @@
+// internal/report/matrix.go
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"kuma-doctor/pkg/analysis"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// GenerateMatrix renderiza las matrices de permisos en el formato indicado
+// (txt, md, csv, json o html).
+func GenerateMatrix(format string, matrices []*analysis.PermissionMatrix) (string, error) {
+	switch format {
+	case "txt":
+		return matrixText(matrices), nil
+	case "md":
+		return matrixMarkdown(matrices), nil
+	case "csv":
+		return matrixCSV(matrices)
+	case "json":
+		// Siempre un array, con uno o con varios meshes.
+		if matrices == nil {
+			matrices = []*analysis.PermissionMatrix{}
+		}
+		bytes, err := json.MarshalIndent(matrices, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	case "html":
+		return matrixHTML(matrices)
+	default:
+		return "", fmt.Errorf("formato de reporte desconocido: %s (usa txt, md, csv, json o html)", format)
+	}
+}
+
+// cellSymbol resume una celda: permitido, permitido por un comodín 'kind: Mesh' o denegado.
+func cellSymbol(cell analysis.MatrixCell) string {
+	switch {
+	case cell.Wildcard:
+		return "✅*"
+	case cell.Verdict == analysis.VerdictAllowed:
+		return "✅"
+	default:
+		return "❌"
+	}
+}
+
+const matrixLegend = "✅ permitido · ✅* permitido por una regla 'from' kind: Mesh · ❌ denegado"
+
+func matrixText(matrices []*analysis.PermissionMatrix) string {
+	var sb strings.Builder
+	for i, matrix := range matrices {
+		counts := matrix.Counts()
+		sb.WriteString(bold(fmt.Sprintf("--- Matriz de permisos (mesh %s) ---\n", matrix.Mesh)))
+		sb.WriteString(fmt.Sprintf("Fecha: %s\n", matrix.GeneratedAt.Format(time.RFC1123)))
+		sb.WriteString(fmt.Sprintf("mTLS: %s\n", describeMTLS(matrix.MTLS)))
+		sb.WriteString(fmt.Sprintf("Pares: %d permitidos (%d por comodín), %d denegados\n\n", counts.Allowed, counts.Wildcard, counts.Denied))
+
+		if len(matrix.Sources) == 0 || len(matrix.Destinations) == 0 {
+			sb.WriteString("No hay servicios en el mesh.\n")
+		} else {
+			// Las columnas se numeran para que la tabla quepa en la consola.
+			w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+			header := []string{"ORIGEN \\ DESTINO"}
+			for j := range matrix.Destinations {
+				header = append(header, fmt.Sprintf("[%d]", j+1))
+			}
+			fmt.Fprintln(w, bold(strings.Join(header, "\t")))
+			for r, row := range matrix.Cells {
+				cells := []string{matrix.Sources[r]}
+				for _, cell := range row {
+					cells = append(cells, cellSymbol(cell))
+				}
+				fmt.Fprintln(w, strings.Join(cells, "\t"))
+			}
+			w.Flush()
+			sb.WriteString("\nDestinos:\n")
+			for j, destination := range matrix.Destinations {
+				sb.WriteString(fmt.Sprintf("  [%d] %s\n", j+1, destination))
+			}
+			sb.WriteString(fmt.Sprintf("\n%s\n", matrixLegend))
+		}
+		if i < len(matrices)-1 {
+			sb.WriteString("\n\n")
+		}
+	}
+	return sb.String()
+}
+
+func matrixMarkdown(matrices []*analysis.PermissionMatrix) string {
+	var sb strings.Builder
+	for i, matrix := range matrices {
+		counts := matrix.Counts()
+		sb.WriteString(fmt.Sprintf("## Matriz de permisos (mesh %s)\n\n", matrix.Mesh))
+		sb.WriteString(fmt.Sprintf("**Fecha:** %s\n\n", matrix.GeneratedAt.Format(time.RFC1123)))
+		sb.WriteString(fmt.Sprintf("- **mTLS:** %s\n", describeMTLS(matrix.MTLS)))
+		sb.WriteString(fmt.Sprintf("- **Pares:** %d permitidos (%d por comodín), %d denegados\n\n", counts.Allowed, counts.Wildcard, counts.Denied))
+
+		if len(matrix.Sources) == 0 || len(matrix.Destinations) == 0 {
+			sb.WriteString("No hay servicios en el mesh.\n")
+		} else {
+			sb.WriteString("| Origen \\ Destino | " + strings.Join(matrix.Destinations, " | ") + " |\n")
+			sb.WriteString("|---" + strings.Repeat("|---", len(matrix.Destinations)) + "|\n")
+			for r, row := range matrix.Cells {
+				cells := []string{fmt.Sprintf("`%s`", matrix.Sources[r])}
+				for _, cell := range row {
+					cells = append(cells, cellSymbol(cell))
+				}
+				sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+			}
+			sb.WriteString(fmt.Sprintf("\n%s\n", matrixLegend))
+		}
+		if i < len(matrices)-1 {
+			sb.WriteString("\n---\n\n")
+		}
+	}
+	return sb.String()
+}
+
+// matrixCSV genera una fila por par de servicios, apta para hojas de cálculo y herramientas
+// de auditoría.
+func matrixCSV(matrices []*analysis.PermissionMatrix) (string, error) {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	if err := w.Write([]string{"mesh", "from", "to", "verdict", "action", "deciding_policy", "wildcard"}); err != nil {
+		return "", err
+	}
+	for _, matrix := range matrices {
+		for _, row := range matrix.Cells {
+			for _, cell := range row {
+				policy := ""
+				if cell.DecidingPolicy != nil {
+					policy = cell.DecidingPolicy.String()
+				}
+				record := []string{matrix.Mesh, cell.From, cell.To, string(cell.Verdict), cell.Action, policy, fmt.Sprint(cell.Wildcard)}
+				if err := w.Write(record); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+	w.Flush()
+	return sb.String(), w.Error()
+}
+
+// matrixHTML genera un mapa de calor autocontenido (sin recursos externos) para adjuntar a
+// revisiones de seguridad.
+func matrixHTML(matrices []*analysis.PermissionMatrix) (string, error) {
+	var sb strings.Builder
+	if err := matrixTemplate.Execute(&sb, matrices); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+var matrixTemplate = template.Must(template.New("matrix").Funcs(template.FuncMap{
+	"mtls": describeMTLS,
+	"cellClass": func(cell analysis.MatrixCell) string {
+		switch {
+		case cell.Wildcard:
+			return "wildcard"
+		case cell.Verdict == analysis.VerdictAllowed:
+			return "allowed"
+		default:
+			return "denied"
+		}
+	},
+	"symbol": cellSymbol,
+	"date":   func(t time.Time) string { return t.Format(time.RFC1123) },
+}).Parse(`<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>Matriz de permisos de Kuma</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: center; }
+th.source { text-align: right; }
+thead th { writing-mode: vertical-rl; transform: rotate(180deg); white-space: nowrap; }
+.allowed { background: #c8e6c9; }
+.wildcard { background: #ffb74d; font-weight: bold; }
+.denied { background: #ef9a9a; }
+.legend span { display: inline-block; padding: 2px 8px; margin-right: 8px; }
+</style>
+</head>
+<body>
+<h1>Matriz de permisos de Kuma</h1>
+<p class="legend"><span class="allowed">✅ permitido</span><span class="wildcard">✅* permitido por una regla 'from' kind: Mesh</span><span class="denied">❌ denegado</span></p>
+{{range .}}{{$matrix := .}}{{$counts := .Counts}}
+<h2>Mesh {{.Mesh}}</h2>
+<p>Fecha: {{date .GeneratedAt}} · mTLS: {{mtls .MTLS}} · {{$counts.Allowed}} pares permitidos ({{$counts.Wildcard}} por comodín), {{$counts.Denied}} denegados</p>
+{{if and .Sources .Destinations}}
+<table>
+<thead><tr><th>Origen \ Destino</th>{{range .Destinations}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range $i, $row := .Cells}}<tr><th class="source">{{index $matrix.Sources $i}}</th>{{range $row}}<td class="{{cellClass .}}" title="{{.From}} → {{.To}}: {{.Verdict}}{{if .Action}} ({{.Action}}{{if .DecidingPolicy}}, {{.DecidingPolicy}}{{end}}){{end}}">{{symbol .}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{else}}
+<p>No hay servicios en el mesh.</p>
+{{end}}{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,87 @@
+// internal/report/reachability.go
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"kuma-doctor/pkg/analysis"
+	"strings"
+	"text/tabwriter"
+)
+
+// GenerateReachability renderiza el resultado de 'can-i-reach' en el formato indicado
+// (txt, md o json).
+func GenerateReachability(format string, reachability *analysis.Reachability) (string, error) {
+	switch format {
+	case "txt":
+		return reachabilityText(reachability), nil
+	case "md":
+		return reachabilityMarkdown(reachability), nil
+	case "json":
+		bytes, err := json.MarshalIndent(reachability, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	default:
+		return "", fmt.Errorf("formato de reporte desconocido: %s", format)
+	}
+}
+
+func reachabilityText(r *analysis.Reachability) string {
+	var sb strings.Builder
+	sb.WriteString(bold(fmt.Sprintf("--- ¿Puede %s alcanzar a %s? (mesh %s) ---\n", r.From, r.To, r.Mesh)))
+	if r.Verdict == analysis.VerdictAllowed {
+		sb.WriteString(green("✅ PERMITIDO\n"))
+	} else {
+		sb.WriteString(red("❌ DENEGADO\n"))
+	}
+	sb.WriteString(fmt.Sprintf("Motivo: %s\n", r.Reason))
+	sb.WriteString(fmt.Sprintf("mTLS: %s\n", describeMTLS(r.MTLS)))
+	if r.DecidingPolicy != nil {
+		sb.WriteString(fmt.Sprintf("Política decisiva: %s (from %s, %s)\n", r.DecidingPolicy, r.DecidingRule, r.Action))
+	}
+
+	if len(r.Steps) > 0 {
+		sb.WriteString("\nReglas aplicadas (en orden de fusión, la última decide):\n")
+		w := tabwriter.NewWriter(&sb, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, bold("  #\tPOLÍTICA\tTARGETREF\tFROM\tACCIÓN"))
+		for i, step := range r.Steps {
+			fmt.Fprintf(w, "  %d\t%s\t%s\t%s\t%s\n", i+1, step.Policy, step.TargetRef, step.From, step.Action)
+		}
+		w.Flush()
+	}
+	return sb.String()
+}
+
+func reachabilityMarkdown(r *analysis.Reachability) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## ¿Puede `%s` alcanzar a `%s`?\n\n", r.From, r.To))
+	verdict := "❌ **DENEGADO**"
+	if r.Verdict == analysis.VerdictAllowed {
+		verdict = "✅ **PERMITIDO**"
+	}
+	sb.WriteString(fmt.Sprintf("%s\n\n", verdict))
+	sb.WriteString(fmt.Sprintf("- **Mesh:** %s\n", r.Mesh))
+	sb.WriteString(fmt.Sprintf("- **Motivo:** %s\n", r.Reason))
+	sb.WriteString(fmt.Sprintf("- **mTLS:** %s\n", describeMTLS(r.MTLS)))
+	if r.DecidingPolicy != nil {
+		sb.WriteString(fmt.Sprintf("- **Política decisiva:** `%s` (from `%s`, %s)\n", r.DecidingPolicy, r.DecidingRule, r.Action))
+	}
+
+	if len(r.Steps) > 0 {
+		sb.WriteString("\n| # | Política | TargetRef | From | Acción |\n")
+		sb.WriteString("|---|---|---|---|---|\n")
+		for i, step := range r.Steps {
+			sb.WriteString(fmt.Sprintf("| %d | `%s` | `%s` | `%s` | %s |\n", i+1, step.Policy, step.TargetRef, step.From, step.Action))
+		}
+	}
+	return sb.String()
+}
+
+func describeMTLS(state analysis.MTLSState) string {
+	if !state.Enabled {
+		return "desactivado"
+	}
+	return fmt.Sprintf("activado (backend %s, modo %s)", state.Backend, state.Mode)
+}
@@ -2,33 +2,47 @@
 package tui
 
 import (
+	"context"
 	"fmt"
-	"kuma-doctor/internal/kubernetes"
 	"kuma-doctor/internal/report"
 	"kuma-doctor/pkg/analysis"
 	"os"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
-	"k8s.io/client-go/dynamic"
 )
 
+// Opciones fijas del menú que no corresponden a un analizador concreto.
+const (
+	fullReportOption = "Generar Reporte Completo"
+	exitOption       = "Salir"
+)
+
+// EnvFactory construye el entorno de análisis (conexión y alcance) a partir de los flags de la CLI.
+type EnvFactory func() (*analysis.Env, error)
+
+// ContextFactory crea el contexto de cada análisis (--timeout y cancelación con Ctrl-C).
+// Se crea uno nuevo por cada opción elegida, de modo que Ctrl-C cancela el análisis en
+// curso y vuelve al menú.
+type ContextFactory func() (context.Context, context.CancelFunc)
+
 // ShowInteractiveMenu muestra el menú principal y maneja la selección del usuario.
-func ShowInteractiveMenu(outputFormat, outputFile string) error {
+// Las opciones se generan a partir de los analizadores registrados en pkg/analysis.
+func ShowInteractiveMenu(newContext ContextFactory, newEnv EnvFactory, outputFormat, outputFile string) error {
+	analyzers := analysis.Analyzers()
+	options := []string{fullReportOption}
+	byTitle := make(map[string]analysis.Analyzer, len(analyzers))
+	for _, a := range analyzers {
+		options = append(options, a.Title())
+		byTitle[a.Title()] = a
+	}
+	options = append(options, exitOption)
+
 	for {
 		choice := ""
 		prompt := &survey.Select{
-			Message: "¿Qué aspecto de Kuma Mesh deseas analizar?",
-			Options: []string{
-				"Generar Reporte Completo", // <-- Nueva opción funcional
-				"Resumen General de Salud",
-				"Estado de todos los Dataplanes (Proxies)",
-				"Consistencia de Políticas de Tráfico (MeshTrafficPermission)",
-				"Configuración de mTLS (Seguridad)",
-				"Políticas de Resiliencia (Retries, Timeouts, etc.)",
-				"Políticas de Observabilidad (Logs, Metrics, Traces)",
-				"Salir",
-			},
+			Message:  "¿Qué aspecto de Kuma Mesh deseas analizar?",
+			Options:  options,
 			PageSize: 10,
 		}
 		err := survey.AskOne(prompt, &choice)
@@ -38,106 +52,58 @@ func ShowInteractiveMenu(outputFormat, outputFile string) error {
 		}
 
 		switch choice {
-		case "Generar Reporte Completo":
-			handleFullReportAnalysis(outputFormat, outputFile)
-		case "Resumen General de Salud":
-			handleSummaryAnalysis(outputFormat, outputFile)
-		case "Estado de todos los Dataplanes (Proxies)":
-			handleDataplaneAnalysis(outputFormat, outputFile)
-		case "Consistencia de Políticas de Tráfico (MeshTrafficPermission)":
-			handleTrafficPermissionAnalysis(outputFormat, outputFile)
-		case "Configuración de mTLS (Seguridad)":
-			handleMTLSAnalysis(outputFormat, outputFile)
-		case "Políticas de Resiliencia (Retries, Timeouts, etc.)":
-			handleResilienceAnalysis(outputFormat, outputFile)
-		case "Políticas de Observabilidad (Logs, Metrics, Traces)":
-			handleObservabilityAnalysis(outputFormat, outputFile)
-		case "Salir":
+		case fullReportOption:
+			handleFullReportAnalysis(newContext, newEnv, analyzers, outputFormat, outputFile)
+		case exitOption:
 			fmt.Println("¡Hasta luego!")
 			return nil
+		default:
+			if a, ok := byTitle[choice]; ok {
+				executeAnalysis(newContext, newEnv, a, outputFormat, outputFile)
+			}
 		}
-		fmt.Println("\n---\n")
+		fmt.Print("\n---\n\n")
 	}
 }
 
-// --- Nueva función para el Reporte Completo ---
-func handleFullReportAnalysis(outputFormat, outputFile string) {
+// handleFullReportAnalysis ejecuta todos los analizadores y genera un único reporte consolidado.
+func handleFullReportAnalysis(newContext ContextFactory, newEnv EnvFactory, analyzers []analysis.Analyzer, outputFormat, outputFile string) {
 	fmt.Println("Generando reporte completo, esto puede tardar un momento...")
-	client, err := kubernetes.NewClient()
+	env, err := newEnv()
 	if err != nil {
-		fmt.Printf("Error al conectar con Kubernetes: %v\n", err)
+		fmt.Println(err)
 		return
 	}
 
-	// Creamos una lista para almacenar todos los resultados
-	var allResults []*analysis.ValidationResult
-
-	// Ejecutamos cada análisis y añadimos su resultado a la lista
-	if result, err := analysis.AnalyzeSummary(client); err == nil {
-		allResults = append(allResults, result)
-	}
-	if result, err := analysis.AnalyzeDataplanes(client); err == nil {
-		allResults = append(allResults, result)
-	}
-	if result, err := analysis.AnalyzeTrafficPermissions(client); err == nil {
-		allResults = append(allResults, result)
-	}
-	if result, err := analysis.AnalyzeMTLS(client); err == nil {
-		allResults = append(allResults, result)
-	}
-	if result, err := analysis.AnalyzeResilience(client); err == nil {
-		allResults = append(allResults, result)
-	}
-	if result, err := analysis.AnalyzeObservability(client); err == nil {
-		allResults = append(allResults, result)
+	ctx, cancel := newContext()
+	defer cancel()
+	results, err := analysis.RunAll(ctx, env, analyzers)
+	if err != nil {
+		fmt.Printf("Error durante el análisis: %v\n", err)
+		return
 	}
-
 	// Pasamos la lista completa al generador de reportes
-	generateAndDisplayReport(allResults, outputFormat, outputFile)
-}
-
-// --- Handlers Anteriores (Ahora envuelven el resultado en una lista) ---
-
-func handleSummaryAnalysis(outputFormat, outputFile string) {
-	fmt.Println("Generando resumen de salud del mesh...")
-	executeAnalysis(analysis.AnalyzeSummary, outputFormat, outputFile)
-}
-func handleDataplaneAnalysis(outputFormat, outputFile string) {
-	fmt.Println("Analizando Dataplanes...")
-	executeAnalysis(analysis.AnalyzeDataplanes, outputFormat, outputFile)
-}
-func handleTrafficPermissionAnalysis(outputFormat, outputFile string) {
-	fmt.Println("Analizando consistencia de MeshTrafficPermissions...")
-	executeAnalysis(analysis.AnalyzeTrafficPermissions, outputFormat, outputFile)
-}
-func handleMTLSAnalysis(outputFormat, outputFile string) {
-	fmt.Println("Analizando configuración de mTLS...")
-	executeAnalysis(analysis.AnalyzeMTLS, outputFormat, outputFile)
-}
-func handleResilienceAnalysis(outputFormat, outputFile string) {
-	fmt.Println("Analizando políticas de resiliencia...")
-	executeAnalysis(analysis.AnalyzeResilience, outputFormat, outputFile)
-}
-func handleObservabilityAnalysis(outputFormat, outputFile string) {
-	fmt.Println("Analizando políticas de observabilidad...")
-	executeAnalysis(analysis.AnalyzeObservability, outputFormat, outputFile)
+	generateAndDisplayReport(results, outputFormat, outputFile)
 }
 
-// --- Funciones Helper (Actualizadas para el nuevo Reporter) ---
+// --- Funciones Helper ---
 
-func executeAnalysis(analysisFunc func(client dynamic.Interface) (*analysis.ValidationResult, error), outputFormat, outputFile string) {
-	client, err := kubernetes.NewClient()
+func executeAnalysis(newContext ContextFactory, newEnv EnvFactory, analyzer analysis.Analyzer, outputFormat, outputFile string) {
+	fmt.Printf("Ejecutando análisis: %s...\n", analyzer.Title())
+	env, err := newEnv()
 	if err != nil {
-		fmt.Printf("Error al conectar con Kubernetes: %v\n", err)
+		fmt.Println(err)
 		return
 	}
-	result, err := analysisFunc(client)
+	// Un resultado por cada mesh seleccionado
+	ctx, cancel := newContext()
+	defer cancel()
+	results, err := analysis.RunAnalyzer(ctx, env, analyzer)
 	if err != nil {
 		fmt.Printf("Error durante el análisis: %v\n", err)
 		return
 	}
-	// Envolvemos el resultado único en una lista para usar la nueva interfaz del reporter
-	generateAndDisplayReport([]*analysis.ValidationResult{result}, outputFormat, outputFile)
+	generateAndDisplayReport(results, outputFormat, outputFile)
 }
 
 func generateAndDisplayReport(results []*analysis.ValidationResult, outputFormat, outputFile string) {
@@ -0,0 +1,298 @@
+// pkg/analysis/analyzer.go
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Category agrupa los analizadores por área temática (salud, seguridad, resiliencia...).
+type Category string
+
+const (
+	CategoryGeneral       Category = "general"
+	CategoryDataplanes    Category = "dataplanes"
+	CategoryPolicies      Category = "policies"
+	CategorySecurity      Category = "security"
+	CategoryResilience    Category = "resilience"
+	CategoryObservability Category = "observability"
+)
+
+// categoryOrder define el orden en que se presentan las categorías en reportes y menús.
+// Las categorías que no aparecen aquí se ordenan al final.
+var categoryOrder = []Category{
+	CategoryGeneral,
+	CategoryDataplanes,
+	CategoryPolicies,
+	CategorySecurity,
+	CategoryResilience,
+	CategoryObservability,
+}
+
+// Analyzer es la interfaz común que implementa cada chequeo de kuma-doctor.
+// Los comandos 'report' y 'check' y el menú interactivo se generan a partir de los
+// analizadores registrados, por lo que añadir un chequeo nuevo solo requiere
+// implementar esta interfaz y llamar a Register desde un init().
+type Analyzer interface {
+	// ID es el identificador estable del analizador; se usa como nombre del subcomando 'check'.
+	ID() string
+	// Title es la descripción legible que se muestra en el menú y en la ayuda de la CLI.
+	Title() string
+	// Category indica el área a la que pertenece el analizador.
+	Category() Category
+	// Run ejecuta el análisis contra el clúster y el alcance descritos por env. Las consultas
+	// deben usar ctx, que se cancela con --timeout o con Ctrl-C.
+	Run(ctx context.Context, env *Env) (*ValidationResult, error)
+}
+
+// Aliaser es una interfaz opcional para los analizadores que exponen nombres alternativos
+// para su subcomando (por ejemplo 'mtp' para 'traffic-permissions').
+type Aliaser interface {
+	Aliases() []string
+}
+
+// ClusterScoper es una interfaz opcional para los analizadores que revisan recursos de todo el
+// clúster (CRDs, control plane, namespaces...) en lugar de los de un mesh. RunAll los ejecuta
+// una sola vez, con env.Mesh vacío, en lugar de una vez por mesh.
+type ClusterScoper interface {
+	ClusterScoped() bool
+}
+
+// AnalyzerFunc es la firma de las funciones de análisis existentes (AnalyzeMTLS, AnalyzeDataplanes...).
+type AnalyzerFunc func(ctx context.Context, env *Env) (*ValidationResult, error)
+
+// funcAnalyzer adapta una AnalyzerFunc a la interfaz Analyzer.
+type funcAnalyzer struct {
+	id       string
+	title    string
+	category Category
+	aliases  []string
+	run      AnalyzerFunc
+	cluster  bool
+}
+
+// NewAnalyzer construye un Analyzer a partir de una función de análisis.
+func NewAnalyzer(id, title string, category Category, run AnalyzerFunc, aliases ...string) Analyzer {
+	return &funcAnalyzer{id: id, title: title, category: category, aliases: aliases, run: run}
+}
+
+// NewClusterAnalyzer construye un Analyzer de ámbito de clúster (ver ClusterScoper).
+func NewClusterAnalyzer(id, title string, category Category, run AnalyzerFunc, aliases ...string) Analyzer {
+	return &funcAnalyzer{id: id, title: title, category: category, aliases: aliases, run: run, cluster: true}
+}
+
+func (a *funcAnalyzer) ID() string          { return a.id }
+func (a *funcAnalyzer) Title() string       { return a.title }
+func (a *funcAnalyzer) Category() Category  { return a.category }
+func (a *funcAnalyzer) Aliases() []string   { return a.aliases }
+func (a *funcAnalyzer) ClusterScoped() bool { return a.cluster }
+func (a *funcAnalyzer) Run(ctx context.Context, env *Env) (*ValidationResult, error) {
+	return a.run(ctx, env)
+}
+
+// --- Registro central de analizadores ---
+
+var (
+	registryMu sync.RWMutex
+	registry   []Analyzer
+)
+
+// Register añade un analizador al registro central. Hace panic si el ID ya está registrado,
+// igual que database/sql con los drivers duplicados, porque es un error de programación.
+func Register(a Analyzer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, existing := range registry {
+		if existing.ID() == a.ID() {
+			panic(fmt.Sprintf("analysis: analizador registrado dos veces: %s", a.ID()))
+		}
+	}
+	registry = append(registry, a)
+}
+
+// Analyzers devuelve todos los analizadores registrados, ordenados por categoría y,
+// dentro de cada categoría, por orden de registro.
+func Analyzers() []Analyzer {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	analyzers := make([]Analyzer, len(registry))
+	copy(analyzers, registry)
+	sort.SliceStable(analyzers, func(i, j int) bool {
+		return categoryRank(analyzers[i].Category()) < categoryRank(analyzers[j].Category())
+	})
+	return analyzers
+}
+
+// Lookup busca un analizador por su ID o por uno de sus alias.
+func Lookup(id string) (Analyzer, bool) {
+	for _, a := range Analyzers() {
+		if a.ID() == id {
+			return a, true
+		}
+		if aliaser, ok := a.(Aliaser); ok {
+			for _, alias := range aliaser.Aliases() {
+				if alias == id {
+					return a, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// RunAll ejecuta los analizadores de ámbito de clúster (ver ClusterScoper) una sola vez y el
+// resto una vez por cada mesh seleccionado. Devuelve primero los resultados de clúster, sin
+// mesh, y después los demás agrupados por mesh (todos los del primer mesh, luego los del
+// segundo...). Hasta env.Concurrency análisis se ejecutan en paralelo, pero el orden del
+// resultado es siempre el mismo que en una ejecución secuencial.
+//
+// Un analizador que falla no interrumpe al resto: su resultado queda con StatusError y el
+// motivo en Reason. Si ctx se cancela, los análisis pendientes quedan con StatusSkipped.
+// Solo se devuelve un error si no se pueden resolver los meshes.
+func RunAll(ctx context.Context, env *Env, analyzers []Analyzer) ([]*ValidationResult, error) {
+	type job struct {
+		env      *Env
+		analyzer Analyzer
+	}
+	var jobs []job
+	var meshScoped []Analyzer
+	clusterEnv := env.forMesh("")
+	for _, a := range analyzers {
+		if isClusterScoped(a) {
+			jobs = append(jobs, job{env: clusterEnv, analyzer: a})
+		} else {
+			meshScoped = append(meshScoped, a)
+		}
+	}
+	if len(meshScoped) > 0 {
+		meshes, err := ResolveMeshes(ctx, env)
+		if err != nil {
+			return nil, err
+		}
+		for _, mesh := range meshes {
+			meshEnv := env.forMesh(mesh)
+			for _, a := range meshScoped {
+				jobs = append(jobs, job{env: meshEnv, analyzer: a})
+			}
+		}
+	}
+
+	// Cada trabajo escribe en su propia posición, así el orden no depende de cuál termina antes.
+	results := make([]*ValidationResult, len(jobs))
+	runPool(ctx, env.Concurrency, len(jobs), func(i int) {
+		results[i] = runInMesh(ctx, jobs[i].env, jobs[i].analyzer)
+	})
+	for i, result := range results {
+		if result == nil {
+			results[i] = skippedResult(jobs[i].env, jobs[i].analyzer, cancelReason(ctx.Err()))
+		}
+	}
+	return results, nil
+}
+
+// isClusterScoped indica si un analizador es de ámbito de clúster.
+func isClusterScoped(a Analyzer) bool {
+	scoper, ok := a.(ClusterScoper)
+	return ok && scoper.ClusterScoped()
+}
+
+// RunAnalyzer ejecuta un único analizador, una vez o en cada mesh seleccionado según su
+// ámbito, con la misma semántica que RunAll.
+func RunAnalyzer(ctx context.Context, env *Env, a Analyzer) ([]*ValidationResult, error) {
+	return RunAll(ctx, env, []Analyzer{a})
+}
+
+// runPool ejecuta run(0..n-1) con como máximo workers goroutines a la vez. Con workers <= 1
+// la ejecución es secuencial. Los trabajos pendientes no se inician si ctx se cancela.
+func runPool(ctx context.Context, workers, n int, run func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				// select elige al azar si ctx ya se canceló y hay un trabajador libre, así
+				// que el trabajador también comprueba ctx antes de empezar.
+				if ctx.Err() != nil {
+					continue
+				}
+				run(i)
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+}
+
+// runInMesh ejecuta un analizador y convierte su error, si lo hay, en un resultado con StatusError.
+func runInMesh(ctx context.Context, env *Env, a Analyzer) *ValidationResult {
+	result, err := a.Run(ctx, env)
+	if err != nil {
+		return &ValidationResult{
+			Title:       a.Title(),
+			Mesh:        env.Mesh,
+			GeneratedAt: time.Now(),
+			Status:      StatusError,
+			Reason:      err.Error(),
+			Findings:    []Finding{},
+		}
+	}
+	result.Mesh = env.Mesh
+	if result.Status == "" {
+		result.Status = StatusOK
+	}
+	// Un análisis sin hallazgos se serializa como "findings": [], igual que los omitidos.
+	if result.Findings == nil {
+		result.Findings = []Finding{}
+	}
+	return result
+}
+
+// skippedResult construye el resultado de un análisis que no se ejecutó.
+func skippedResult(env *Env, a Analyzer, reason string) *ValidationResult {
+	return &ValidationResult{
+		Title:       a.Title(),
+		Mesh:        env.Mesh,
+		GeneratedAt: time.Now(),
+		Status:      StatusSkipped,
+		Reason:      reason,
+		Findings:    []Finding{},
+	}
+}
+
+func cancelReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "no se ejecutó: se agotó el tiempo del análisis"
+	}
+	return "no se ejecutó: el análisis se canceló"
+}
+
+func categoryRank(c Category) int {
+	for i, known := range categoryOrder {
+		if known == c {
+			return i
+		}
+	}
+	return len(categoryOrder)
+}
@@ -0,0 +1,165 @@
+// pkg/analysis/analyzer_test.go
+package analysis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunPool(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		n       int
+		max     int32
+	}{
+		{name: "sin trabajos", workers: 4, n: 0},
+		{name: "secuencial", workers: 1, n: 5, max: 1},
+		{name: "workers no válido es secuencial", workers: 0, n: 5, max: 1},
+		{name: "como mucho workers a la vez", workers: 3, n: 12, max: 3},
+		{name: "más workers que trabajos", workers: 8, n: 2, max: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			runs := make(map[int]int)
+			var running, peak int32
+			runPool(context.Background(), tt.workers, tt.n, func(i int) {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					seen := atomic.LoadInt32(&peak)
+					if current <= seen || atomic.CompareAndSwapInt32(&peak, seen, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				mu.Lock()
+				runs[i]++
+				mu.Unlock()
+			})
+			if len(runs) != tt.n {
+				t.Errorf("se ejecutaron %d trabajos, se esperaban %d", len(runs), tt.n)
+			}
+			for i, count := range runs {
+				if count != 1 {
+					t.Errorf("el trabajo %d se ejecutó %d veces", i, count)
+				}
+			}
+			if peak > tt.max {
+				t.Errorf("%d trabajos a la vez, se esperaban como mucho %d", peak, tt.max)
+			}
+		})
+	}
+}
+
+func TestRunPoolCancelled(t *testing.T) {
+	t.Run("contexto ya cancelado", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var runs int32
+		runPool(ctx, 4, 10, func(int) { atomic.AddInt32(&runs, 1) })
+		if runs != 0 {
+			t.Errorf("se ejecutaron %d trabajos con el contexto cancelado", runs)
+		}
+	})
+	t.Run("cancelado durante la ejecución", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var ran []int
+		runPool(ctx, 1, 10, func(i int) {
+			ran = append(ran, i)
+			if i == 2 {
+				cancel()
+			}
+		})
+		if want := []int{0, 1, 2}; !reflect.DeepEqual(ran, want) {
+			t.Errorf("trabajos ejecutados = %v, se esperaba %v", ran, want)
+		}
+	})
+}
+
+func TestRunInMesh(t *testing.T) {
+	env := &Env{Mesh: "default"}
+	tests := []struct {
+		name     string
+		run      AnalyzerFunc
+		status   ResultStatus
+		reason   string
+		findings int
+	}{
+		{
+			name: "error del analizador",
+			run: func(context.Context, *Env) (*ValidationResult, error) {
+				return nil, errors.New("error al listar Dataplanes: timeout")
+			},
+			status: StatusError,
+			reason: "error al listar Dataplanes: timeout",
+		},
+		{
+			name: "sin hallazgos",
+			run: func(context.Context, *Env) (*ValidationResult, error) {
+				return &ValidationResult{Title: "Prueba"}, nil
+			},
+			status: StatusOK,
+		},
+		{
+			name: "conserva el estado del analizador",
+			run: func(context.Context, *Env) (*ValidationResult, error) {
+				return &ValidationResult{Title: "Prueba", Status: StatusSkipped, Reason: "no aplica"}, nil
+			},
+			status: StatusSkipped,
+			reason: "no aplica",
+		},
+		{
+			name: "con hallazgos",
+			run: func(context.Context, *Env) (*ValidationResult, error) {
+				return &ValidationResult{Title: "Prueba", Findings: []Finding{{RuleID: "KD-TEST-001"}}}, nil
+			},
+			status:   StatusOK,
+			findings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runInMesh(context.Background(), env, NewAnalyzer("prueba", "Prueba", CategoryGeneral, tt.run))
+			if result.Status != tt.status || result.Reason != tt.reason {
+				t.Errorf("estado = %s (%q), se esperaba %s (%q)", result.Status, result.Reason, tt.status, tt.reason)
+			}
+			if result.Title != "Prueba" || result.Mesh != "default" {
+				t.Errorf("título y mesh = %q, %q, se esperaba Prueba, default", result.Title, result.Mesh)
+			}
+			if result.Findings == nil || len(result.Findings) != tt.findings {
+				t.Errorf("hallazgos = %#v, se esperaban %d (nunca nil)", result.Findings, tt.findings)
+			}
+		})
+	}
+}
+
+func TestRunAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := NewAnalyzer("primero", "Primero", CategoryGeneral, func(context.Context, *Env) (*ValidationResult, error) {
+		cancel()
+		return &ValidationResult{Title: "Primero"}, nil
+	})
+	second := NewAnalyzer("segundo", "Segundo", CategoryGeneral, func(context.Context, *Env) (*ValidationResult, error) {
+		return &ValidationResult{Title: "Segundo"}, nil
+	})
+
+	results, err := RunAll(ctx, &Env{Meshes: []string{"default"}, Concurrency: 1}, []Analyzer{first, second})
+	if err != nil {
+		t.Fatalf("RunAll: %v", err)
+	}
+	if len(results) != 2 || results[0].Status != StatusOK || results[1].Status != StatusSkipped {
+		t.Fatalf("resultados = %+v, se esperaba el primero OK y el segundo omitido", results)
+	}
+	if results[1].Title != "Segundo" || results[1].Reason != cancelReason(context.Canceled) {
+		t.Errorf("resultado omitido = %q (%q)", results[1].Title, results[1].Reason)
+	}
+}
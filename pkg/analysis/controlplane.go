@@ -0,0 +1,392 @@
+// pkg/analysis/controlplane.go
+package analysis
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func init() {
+	Register(NewClusterAnalyzer("control-plane", "Salud del Control Plane (Deployment, Endpoints, Webhooks, Líder)", CategoryGeneral, AnalyzeControlPlane, "cp"))
+}
+
+const controlPlaneTitle = "Análisis de Salud del Control Plane"
+
+// Ubicación del control plane en Kubernetes, la que usan kumactl y el chart de Helm por defecto.
+const (
+	controlPlaneNamespace  = "kuma-system"
+	controlPlaneDeployment = "kuma-control-plane"
+	// controlPlaneService es también el Service al que apuntan los webhooks de admisión.
+	controlPlaneService = "kuma-control-plane"
+	// controlPlaneLease es el lease con el que las réplicas del control plane eligen líder.
+	controlPlaneLease = "cp-leader-lease"
+)
+
+// webhookCertWindow es la antelación con la que se avisa de que la CA de un webhook caduca.
+// Son certificados de larga duración que suelen renovarse a mano o con cert-manager.
+const webhookCertWindow = 30 * 24 * time.Hour
+
+var controlPlaneMissingReason = fmt.Sprintf(
+	"no se encontró el Deployment %s/%s del control plane (en Universal no existe)", controlPlaneNamespace, controlPlaneDeployment,
+)
+
+// AnalyzeControlPlane revisa el propio control plane en Kubernetes: las réplicas listas y los
+// reinicios de su Deployment, los endpoints de su Service, los webhooks de admisión que apuntan
+// a él (y la CA con la que el API server valida su certificado) y el lease de elección de líder.
+func AnalyzeControlPlane(ctx context.Context, env *Env) (*ValidationResult, error) {
+	result := &ValidationResult{Title: controlPlaneTitle, GeneratedAt: time.Now()}
+
+	deployment, err := env.Source.Get(ctx, DeploymentType, controlPlaneNamespace, controlPlaneDeployment)
+	if err != nil {
+		if isMissing(err) {
+			result.Status, result.Reason = StatusSkipped, controlPlaneMissingReason
+			return result, nil
+		}
+		return nil, fmt.Errorf("error al leer el Deployment del control plane: %w", err)
+	}
+	result.Findings = append(result.Findings, deploymentFinding(*deployment))
+
+	pods, err := controlPlanePods(ctx, env, *deployment)
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, unavailableDiagnostic(PodType, err))
+	}
+	result.Findings = append(result.Findings, restartFindings(pods)...)
+
+	findings, diagnostics := endpointsFindings(ctx, env)
+	result.Findings = append(result.Findings, findings...)
+	result.Diagnostics = append(result.Diagnostics, diagnostics...)
+
+	findings, diagnostics = webhookFindings(ctx, env, time.Now())
+	result.Findings = append(result.Findings, findings...)
+	result.Diagnostics = append(result.Diagnostics, diagnostics...)
+
+	finding, err := leaseFinding(ctx, env, pods, time.Now())
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, unavailableDiagnostic(LeaseType, err))
+	} else {
+		result.Findings = append(result.Findings, finding)
+	}
+	return result, nil
+}
+
+// isMissing indica si err se debe a que no existe el recurso o su tipo.
+func isMissing(err error) bool {
+	return IsNotInstalled(err) || apierrors.IsNotFound(err)
+}
+
+// unavailableDiagnostic describe un tipo de recurso que no se pudo revisar.
+func unavailableDiagnostic(rt ResourceType, err error) string {
+	if IsNotInstalled(err) {
+		return fmt.Sprintf("el origen de datos no incluye recursos %s; no se revisan", rt.Kind)
+	}
+	return fmt.Sprintf("no se pudieron leer los recursos %s: %v", rt.Kind, err)
+}
+
+// controlPlaneImage devuelve la imagen del contenedor del control plane en su Deployment.
+func controlPlaneImage(deployment unstructured.Unstructured) string {
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	var first string
+	for _, item := range containers {
+		container, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		image, _, _ := unstructured.NestedString(container, "image")
+		name, _, _ := unstructured.NestedString(container, "name")
+		if name == "control-plane" || strings.Contains(image, "kuma-cp") {
+			return image
+		}
+		if first == "" {
+			first = image
+		}
+	}
+	return first
+}
+
+func deploymentFinding(deployment unstructured.Unstructured) Finding {
+	ref := ResourceRef{Kind: "Deployment", Namespace: controlPlaneNamespace, Name: controlPlaneDeployment}
+	desired, found, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	if !found {
+		desired = 1 // Valor por defecto de Kubernetes
+	}
+	ready, _, _ := unstructured.NestedInt64(deployment.Object, "status", "readyReplicas")
+	image := orUnknown(controlPlaneImage(deployment))
+
+	switch {
+	case ready == 0:
+		return RuleControlPlaneUnavailable.Finding(ref, fmt.Sprintf(
+			"Ninguna de las %d réplicas del control plane está lista (imagen %s): los Dataplanes no reciben configuración y los pods nuevos no se inyectan.", desired, image))
+	case ready < desired:
+		return RuleControlPlaneDegraded.Finding(ref, fmt.Sprintf(
+			"Solo %d de %d réplicas del control plane están listas (imagen %s).", ready, desired, image))
+	default:
+		return RuleControlPlaneHealthy.Finding(ref, fmt.Sprintf(
+			"%d/%d réplicas del control plane listas (imagen %s).", ready, desired, image))
+	}
+}
+
+// controlPlanePods devuelve los pods que selecciona el Deployment del control plane.
+func controlPlanePods(ctx context.Context, env *Env, deployment unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	pods, err := env.Source.List(ctx, PodType, controlPlaneNamespace)
+	if err != nil {
+		return nil, err
+	}
+	selector, _, _ := unstructured.NestedStringMap(deployment.Object, "spec", "selector", "matchLabels")
+	if len(selector) == 0 {
+		return nil, nil
+	}
+	var selected []unstructured.Unstructured
+	for _, pod := range pods {
+		if tagsMatch(selector, pod.GetLabels()) {
+			selected = append(selected, pod)
+		}
+	}
+	return selected, nil
+}
+
+// restartFindings informa de los pods del control plane cuyos contenedores se han reiniciado.
+func restartFindings(pods []unstructured.Unstructured) []Finding {
+	var findings []Finding
+	for _, pod := range pods {
+		statuses, _, _ := unstructured.NestedSlice(pod.Object, "status", "containerStatuses")
+		var restarts int64
+		var reasons []string
+		for _, item := range statuses {
+			status, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			count, _, _ := unstructured.NestedInt64(status, "restartCount")
+			restarts += count
+			if reason, _, _ := unstructured.NestedString(status, "lastState", "terminated", "reason"); reason != "" && count > 0 {
+				reasons = append(reasons, reason)
+			}
+		}
+		if restarts == 0 {
+			continue
+		}
+		message := fmt.Sprintf("El pod del control plane se ha reiniciado %d veces", restarts)
+		if len(reasons) > 0 {
+			message += fmt.Sprintf(" (último motivo: %s)", strings.Join(reasons, ", "))
+		}
+		findings = append(findings, RuleControlPlaneRestarts.Finding(
+			ResourceRef{Kind: "Pod", Namespace: pod.GetNamespace(), Name: pod.GetName()}, message+".",
+		))
+	}
+	return findings
+}
+
+// endpointsFindings comprueba que el Service del control plane tiene endpoints listos: es la
+// dirección a la que se conectan los Dataplanes (xDS) y a la que llama el API server (webhooks).
+func endpointsFindings(ctx context.Context, env *Env) ([]Finding, []string) {
+	ref := ResourceRef{Kind: "Service", Namespace: controlPlaneNamespace, Name: controlPlaneService}
+	if _, err := env.Source.Get(ctx, ServiceType, controlPlaneNamespace, controlPlaneService); err != nil {
+		if IsNotInstalled(err) || !apierrors.IsNotFound(err) {
+			return nil, []string{unavailableDiagnostic(ServiceType, err)}
+		}
+		return []Finding{RuleControlPlaneNoEndpoints.Finding(ref, "No existe el Service del control plane.")}, nil
+	}
+
+	var ready, notReady int
+	endpoints, err := env.Source.Get(ctx, EndpointsType, controlPlaneNamespace, controlPlaneService)
+	switch {
+	case IsNotInstalled(err):
+		return nil, []string{unavailableDiagnostic(EndpointsType, err)}
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, []string{unavailableDiagnostic(EndpointsType, err)}
+	default:
+		subsets, _, _ := unstructured.NestedSlice(endpoints.Object, "subsets")
+		for _, item := range subsets {
+			subset, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			addresses, _, _ := unstructured.NestedSlice(subset, "addresses")
+			pending, _, _ := unstructured.NestedSlice(subset, "notReadyAddresses")
+			ready += len(addresses)
+			notReady += len(pending)
+		}
+	}
+
+	switch {
+	case ready == 0:
+		return []Finding{RuleControlPlaneNoEndpoints.Finding(ref, fmt.Sprintf(
+			"El Service del control plane no tiene endpoints listos (%d no listos): los Dataplanes no pueden conectarse y los webhooks fallan.", notReady))}, nil
+	case notReady > 0:
+		return []Finding{RuleControlPlaneEndpointsNotReady.Finding(ref, fmt.Sprintf(
+			"%d endpoints del Service del control plane no están listos (%d listos).", notReady, ready))}, nil
+	}
+	return nil, nil
+}
+
+// webhookBundle son los webhooks de una configuración que comparten el mismo caBundle.
+type webhookBundle struct {
+	config   ResourceRef
+	caBundle string
+	webhooks []string
+}
+
+// webhookFindings revisa los webhooks de admisión (mutating y validating) que apuntan al
+// Service del control plane: que existan y que su caBundle contenga una CA vigente, sin la que
+// el API server rechaza la llamada y, según su failurePolicy, la inyección o la validación.
+func webhookFindings(ctx context.Context, env *Env, now time.Time) ([]Finding, []string) {
+	var findings []Finding
+	var diagnostics []string
+	var bundles []webhookBundle
+	readable := 0
+	for _, rt := range []ResourceType{MutatingWebhookConfigurationType, ValidatingWebhookConfigurationType} {
+		configs, err := env.Source.List(ctx, rt, "")
+		if err != nil {
+			diagnostics = append(diagnostics, unavailableDiagnostic(rt, err))
+			continue
+		}
+		readable++
+		for _, config := range configs {
+			bundles = append(bundles, controlPlaneWebhooks(rt, config)...)
+		}
+	}
+	if len(bundles) == 0 {
+		if readable > 0 {
+			findings = append(findings, RuleControlPlaneNoWebhooks.Finding(
+				ResourceRef{Kind: "Service", Namespace: controlPlaneNamespace, Name: controlPlaneService},
+				"Ninguna MutatingWebhookConfiguration ni ValidatingWebhookConfiguration apunta al control plane: los sidecars no se inyectan y los recursos de Kuma no se validan.",
+			))
+		}
+		return findings, diagnostics
+	}
+
+	var earliest *time.Time
+	for _, bundle := range bundles {
+		names := strings.Join(bundle.webhooks, ", ")
+		expiration, err := caBundleExpiration(bundle.caBundle)
+		switch {
+		case err != nil:
+			findings = append(findings, RuleWebhookCABundleInvalid.Finding(bundle.config, fmt.Sprintf("Webhooks %s: %v.", names, err)))
+		case !expiration.After(now):
+			findings = append(findings, RuleWebhookCAExpired.Finding(bundle.config, fmt.Sprintf(
+				"Webhooks %s: la CA del caBundle caducó el %s; el API server rechaza las llamadas al control plane.", names, formatTime(&expiration))))
+		case expiration.Sub(now) <= webhookCertWindow:
+			findings = append(findings, RuleWebhookCAExpiring.Finding(bundle.config, fmt.Sprintf(
+				"Webhooks %s: la CA del caBundle caduca el %s.", names, formatTime(&expiration))))
+		default:
+			if earliest == nil || expiration.Before(*earliest) {
+				earliest = &expiration
+			}
+		}
+	}
+	if len(findings) == 0 {
+		findings = append(findings, RuleWebhooksOK.Finding(
+			ResourceRef{Kind: "Service", Namespace: controlPlaneNamespace, Name: controlPlaneService},
+			fmt.Sprintf("%d configuración(es) de webhooks apuntan al control plane con una CA válida hasta %s.", len(bundles), formatTime(earliest)),
+		))
+	}
+	return findings, diagnostics
+}
+
+// controlPlaneWebhooks agrupa por caBundle los webhooks de una configuración que llaman al
+// Service del control plane.
+func controlPlaneWebhooks(rt ResourceType, config unstructured.Unstructured) []webhookBundle {
+	webhooks, _, _ := unstructured.NestedSlice(config.Object, "webhooks")
+	byBundle := make(map[string]*webhookBundle)
+	var order []string
+	for _, item := range webhooks {
+		webhook, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		service, _, _ := unstructured.NestedString(webhook, "clientConfig", "service", "name")
+		namespace, _, _ := unstructured.NestedString(webhook, "clientConfig", "service", "namespace")
+		if service != controlPlaneService || namespace != controlPlaneNamespace {
+			continue
+		}
+		caBundle, _, _ := unstructured.NestedString(webhook, "clientConfig", "caBundle")
+		name, _, _ := unstructured.NestedString(webhook, "name")
+		bundle, ok := byBundle[caBundle]
+		if !ok {
+			bundle = &webhookBundle{config: ResourceRef{Kind: rt.Kind, Name: config.GetName()}, caBundle: caBundle}
+			byBundle[caBundle] = bundle
+			order = append(order, caBundle)
+		}
+		bundle.webhooks = append(bundle.webhooks, name)
+	}
+	bundles := make([]webhookBundle, 0, len(order))
+	for _, caBundle := range order {
+		bundles = append(bundles, *byBundle[caBundle])
+	}
+	return bundles
+}
+
+// caBundleExpiration devuelve la caducidad más próxima de los certificados de un caBundle
+// (PEM en base64, tal y como aparece en el recurso).
+func caBundleExpiration(caBundle string) (time.Time, error) {
+	if caBundle == "" {
+		return time.Time{}, fmt.Errorf("no tienen caBundle; el API server no puede validar el certificado del control plane")
+	}
+	data, err := base64.StdEncoding.DecodeString(caBundle)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("el caBundle no es base64 válido")
+	}
+	var expirations []time.Time
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			expirations = append(expirations, cert.NotAfter)
+		}
+	}
+	if len(expirations) == 0 {
+		return time.Time{}, fmt.Errorf("el caBundle no contiene certificados válidos")
+	}
+	sort.Slice(expirations, func(i, j int) bool { return expirations[i].Before(expirations[j]) })
+	return expirations[0], nil
+}
+
+// leaseFinding revisa el lease de elección de líder: sin un líder vigente nadie ejecuta las
+// tareas exclusivas del líder (generación de certificados, limpieza de insights...).
+func leaseFinding(ctx context.Context, env *Env, pods []unstructured.Unstructured, now time.Time) (Finding, error) {
+	ref := ResourceRef{Kind: "Lease", Namespace: controlPlaneNamespace, Name: controlPlaneLease}
+	lease, err := env.Source.Get(ctx, LeaseType, controlPlaneNamespace, controlPlaneLease)
+	if err != nil {
+		if IsNotInstalled(err) || !apierrors.IsNotFound(err) {
+			return Finding{}, err
+		}
+		return RuleControlPlaneNoLeader.Finding(ref, "No existe el lease de elección de líder: ninguna réplica del control plane se ha proclamado líder."), nil
+	}
+
+	holder, _, _ := unstructured.NestedString(lease.Object, "spec", "holderIdentity")
+	renewed := nestedTime(lease.Object, "spec", "renewTime")
+	duration, _, _ := unstructured.NestedInt64(lease.Object, "spec", "leaseDurationSeconds")
+	if holder == "" || renewed == nil || renewed.Add(time.Duration(duration)*time.Second).Before(now) {
+		return RuleControlPlaneNoLeader.Finding(ref, fmt.Sprintf(
+			"El lease no tiene un líder vigente (último titular: %s, renovado: %s).", orUnknown(holder), formatTime(renewed))), nil
+	}
+
+	// La identidad del titular es '<pod>_<uuid>'.
+	pod := strings.SplitN(holder, "_", 2)[0]
+	if len(pods) > 0 && !containsPod(pods, pod) {
+		return RuleControlPlaneLeaderStale.Finding(ref, fmt.Sprintf(
+			"El titular del lease (%s) no es ningún pod actual del control plane.", holder)), nil
+	}
+	return RuleControlPlaneLeader.Finding(ref, fmt.Sprintf(
+		"Líder: %s (renovado hace %s).", pod, now.Sub(*renewed).Round(time.Second))), nil
+}
+
+func containsPod(pods []unstructured.Unstructured, name string) bool {
+	for _, pod := range pods {
+		if pod.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
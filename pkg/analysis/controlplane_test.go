@@ -0,0 +1,186 @@
+// pkg/analysis/controlplane_test.go
+package analysis
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// testCertPEM genera un certificado autofirmado en PEM que caduca en notAfter.
+func testCertPEM(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kuma-ca"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// testCABundle devuelve el caBundle (PEM en base64) de un certificado que caduca en notAfter.
+func testCABundle(t *testing.T, notAfter time.Time) string {
+	t.Helper()
+	return base64.StdEncoding.EncodeToString(testCertPEM(t, notAfter))
+}
+
+func TestCABundleExpiration(t *testing.T) {
+	soon, later := testNow.Add(24*time.Hour), testNow.Add(365*24*time.Hour)
+	tests := []struct {
+		name     string
+		caBundle string
+		want     time.Time
+		wantErr  bool
+	}{
+		{name: "vacío", wantErr: true},
+		{name: "no es base64", caBundle: "%%%", wantErr: true},
+		{name: "sin certificados", caBundle: base64.StdEncoding.EncodeToString([]byte("no es PEM")), wantErr: true},
+		{name: "un certificado", caBundle: testCABundle(t, later), want: later},
+		{
+			name:     "gana la caducidad más próxima",
+			caBundle: base64.StdEncoding.EncodeToString(append(testCertPEM(t, later), testCertPEM(t, soon)...)),
+			want:     soon,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := caBundleExpiration(tt.caBundle)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("caBundleExpiration error = %v, se esperaba error: %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("caBundleExpiration = %s, se esperaba %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// webhookConfiguration devuelve una MutatingWebhookConfiguration con un webhook que apunta al
+// Service service de kuma-system con el caBundle indicado.
+func webhookConfiguration(service, caBundle string) string {
+	return `
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata: {name: kuma-admission-mutating-webhook-configuration}
+webhooks:
+- name: owner-reference.kuma-admission.kuma.io
+  clientConfig:
+    caBundle: "` + caBundle + `"
+    service: {name: ` + service + `, namespace: kuma-system}
+`
+}
+
+func TestWebhookFindings(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifests string
+		want      []string
+	}{
+		{
+			name:      "CA vigente",
+			manifests: webhookConfiguration(controlPlaneService, testCABundle(t, testNow.Add(365*24*time.Hour))),
+			want:      []string{RuleWebhooksOK.ID},
+		},
+		{
+			name:      "CA a punto de caducar",
+			manifests: webhookConfiguration(controlPlaneService, testCABundle(t, testNow.Add(webhookCertWindow-time.Hour))),
+			want:      []string{RuleWebhookCAExpiring.ID},
+		},
+		{
+			name:      "CA caducada",
+			manifests: webhookConfiguration(controlPlaneService, testCABundle(t, testNow.Add(-time.Hour))),
+			want:      []string{RuleWebhookCAExpired.ID},
+		},
+		{
+			name:      "sin caBundle",
+			manifests: webhookConfiguration(controlPlaneService, ""),
+			want:      []string{RuleWebhookCABundleInvalid.ID},
+		},
+		{
+			name:      "webhooks de otro servicio",
+			manifests: webhookConfiguration("cert-manager-webhook", ""),
+			want:      []string{RuleControlPlaneNoWebhooks.ID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, diagnostics := webhookFindings(context.Background(), &Env{Source: newFakeSource(t, tt.manifests)}, testNow)
+			if len(diagnostics) > 0 {
+				t.Errorf("diagnósticos inesperados: %v", diagnostics)
+			}
+			var got []string
+			for _, finding := range findings {
+				got = append(got, finding.RuleID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hallazgos = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaseFinding(t *testing.T) {
+	lease := func(holder string, renewed time.Time) string {
+		return `
+apiVersion: coordination.k8s.io/v1
+kind: Lease
+metadata: {name: cp-leader-lease, namespace: kuma-system}
+spec: {holderIdentity: "` + holder + `", renewTime: "` + renewed.Format(time.RFC3339) + `", leaseDurationSeconds: 15}
+`
+	}
+	pods := newFakeSource(t, `
+apiVersion: v1
+kind: Pod
+metadata: {name: kuma-control-plane-a, namespace: kuma-system}
+`).objects
+
+	tests := []struct {
+		name      string
+		manifests string
+		noPods    bool
+		want      Rule
+	}{
+		{name: "sin lease", want: RuleControlPlaneNoLeader},
+		{name: "lease vigente", manifests: lease("kuma-control-plane-a_1234", testNow.Add(-5*time.Second)), want: RuleControlPlaneLeader},
+		{name: "lease caducado", manifests: lease("kuma-control-plane-a_1234", testNow.Add(-time.Minute)), want: RuleControlPlaneNoLeader},
+		{name: "lease sin titular", manifests: lease("", testNow), want: RuleControlPlaneNoLeader},
+		{name: "titular que ya no existe", manifests: lease("kuma-control-plane-b_5678", testNow), want: RuleControlPlaneLeaderStale},
+		{name: "sin pods con los que comparar", manifests: lease("kuma-control-plane-b_5678", testNow), noPods: true, want: RuleControlPlaneLeader},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := pods
+			if tt.noPods {
+				current = nil
+			}
+			finding, err := leaseFinding(context.Background(), &Env{Source: newFakeSource(t, tt.manifests)}, current, testNow)
+			if err != nil {
+				t.Fatalf("leaseFinding: %v", err)
+			}
+			if finding.RuleID != tt.want.ID {
+				t.Errorf("leaseFinding = %s (%s), se esperaba %s", finding.RuleID, finding.Message, tt.want.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// pkg/analysis/crds.go
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register(NewClusterAnalyzer("crds", "CRDs de Kuma Instalados", CategoryGeneral, AnalyzeCRDs))
+}
+
+// AnalyzeCRDs compara los CRDs del grupo kuma.io que sirve el clúster (API de discovery) con
+// los que usan los analizadores: informa de los que faltan, de los que se sirven en una
+// versión distinta de la esperada y de los que kuma-doctor no conoce.
+func AnalyzeCRDs(ctx context.Context, env *Env) (*ValidationResult, error) {
+	result := &ValidationResult{
+		Title:       "Análisis de CRDs de Kuma",
+		GeneratedAt: time.Now(),
+	}
+
+	discoverer, ok := env.Source.(Discoverer)
+	if !ok {
+		result.Status, result.Reason = StatusSkipped, ErrDiscoveryUnsupported.Error()
+		return result, nil
+	}
+	discovery, err := discoverer.Discover(ctx, kumaGroup)
+	if errors.Is(err, ErrDiscoveryUnsupported) {
+		result.Status, result.Reason = StatusSkipped, err.Error()
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar la API de discovery: %w", err)
+	}
+
+	if !discovery.Installed() {
+		result.Findings = append(result.Findings, RuleKumaNotInstalled.Finding(
+			ResourceRef{Kind: "APIGroup", Name: kumaGroup},
+			"El clúster no sirve el grupo de API kuma.io: Kuma no está instalado o sus CRDs se han eliminado.",
+		))
+		return result, nil
+	}
+
+	known := make(map[string]bool)
+	for _, rt := range KnownResourceTypes() {
+		if rt.GVR.Group != kumaGroup {
+			continue
+		}
+		known[rt.Kind] = true
+
+		versions := discovery.ServedVersions(rt.Kind)
+		switch {
+		case len(versions) == 0:
+			result.Findings = append(result.Findings, RuleCRDNotInstalled.Finding(
+				crdRef(rt),
+				fmt.Sprintf("El CRD de %s no está instalado; se omiten los chequeos que dependen de él.", rt.Kind),
+			))
+		case !contains(versions, rt.GVR.Version):
+			resolved, _ := discovery.Resolve(rt)
+			result.Findings = append(result.Findings, RuleCRDUnexpectedVersion.Finding(
+				crdRef(rt),
+				fmt.Sprintf("%s se sirve en las versiones %s en lugar de %s; se usará %s.",
+					rt.Kind, strings.Join(versions, ", "), rt.GVR.Version, resolved.GVR.Version),
+			))
+		}
+	}
+
+	// Los CRDs de Kuma que ningún analizador usa se agrupan en un único hallazgo informativo.
+	unknown := make(map[string]bool)
+	for _, resources := range discovery.Resources {
+		for kind := range resources {
+			if !known[kind] {
+				unknown[kind] = true
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		result.Findings = append(result.Findings, RuleCRDUnknown.Finding(
+			ResourceRef{Kind: "APIGroup", Name: kumaGroup},
+			fmt.Sprintf("%d CRDs de Kuma no se analizan con kuma-doctor: %s.", len(unknown), strings.Join(sortedKeys(unknown), ", ")),
+		))
+	}
+
+	if onlyInfo(result.Findings) {
+		result.Findings = append(result.Findings, RuleCRDsOK.Finding(
+			ResourceRef{Kind: "APIGroup", Name: kumaGroup},
+			fmt.Sprintf("Todos los CRDs que usa kuma-doctor están instalados (versión preferida: %s).", discovery.PreferredVersion),
+		))
+	}
+	return result, nil
+}
+
+func onlyInfo(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity != SeverityInfo {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
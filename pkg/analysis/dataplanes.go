@@ -4,82 +4,199 @@ package analysis
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
 )
 
-// AnalyzeDataplanes ejecuta la validación de todos los dataplanes y devuelve un resultado estructurado.
-func AnalyzeDataplanes(client dynamic.Interface) (*ValidationResult, error) {
-	dataplaneGVR := schema.GroupVersionResource{
-		Group:    "kuma.io",
-		Version:  "v1alpha1",
-		Resource: "dataplanes",
-	}
+func init() {
+	Register(NewAnalyzer("dataplanes", "Estado de todos los Dataplanes (Proxies)", CategoryDataplanes, AnalyzeDataplanes))
+}
+
+const dataplanesTitle = "Análisis de Estado de Dataplanes"
 
-	unstructuredDataplanes, err := client.Resource(dataplaneGVR).List(context.TODO(), metav1.ListOptions{})
+// AnalyzeDataplanes ejecuta la validación de todos los dataplanes y devuelve un resultado estructurado.
+// Además del estado de los inbounds, cruza cada Dataplane con su DataplaneInsight para detectar
+// proxies desconectados del control plane, suscripciones xDS obsoletas, certificados mTLS a punto
+// de caducar (según env.CertExpiryWindow) y versiones incompatibles. Los Dataplanes que han
+// sobrevivido a su pod o llevan horas sin conectarse se clasifican como Stale (ver
+// staleDataplanes) en lugar de Offline.
+func AnalyzeDataplanes(ctx context.Context, env *Env) (*ValidationResult, error) {
+	unstructuredDataplanes, err := env.Source.List(ctx, DataplaneType, env.Namespace)
 	if err != nil {
+		if result, ok := skipIfNotInstalled(dataplanesTitle, err); ok {
+			return result, nil
+		}
 		return nil, fmt.Errorf("error al listar Dataplanes: %w", err)
 	}
 
 	result := &ValidationResult{
-		Title:       "Análisis de Estado de Dataplanes",
+		Title:       dataplanesTitle,
 		GeneratedAt: time.Now(),
 	}
 
-	for _, dp := range unstructuredDataplanes.Items {
-		status, _ := getDataplaneStatusFromInbounds(dp)
-		finding := DataplaneStatus{
+	dataplanes := filterByMesh(unstructuredDataplanes, env.Mesh)
+	var insights map[string]*DataplaneInsightStatus
+	if len(dataplanes) > 0 {
+		if insights, err = dataplaneInsights(ctx, env); err != nil {
+			result.Diagnostics = append(result.Diagnostics, err.Error())
+		}
+	}
+	window := env.CertExpiryWindow
+	if window <= 0 {
+		window = DefaultCertExpiryWindow
+	}
+	now := time.Now()
+	stale, diagnostics := staleDataplanes(ctx, env, dataplanes, insights, now)
+	result.Diagnostics = append(result.Diagnostics, diagnostics...)
+
+	for _, dp := range dataplanes {
+		status, inbounds := getDataplaneStatusFromInbounds(dp)
+		reason, isStale := stale[dataplaneKey(dp)]
+		if isStale {
+			status = derivedStatus{"Stale", reason}
+		}
+		ref := ResourceRef{Kind: "Dataplane", Mesh: meshOf(dp), Namespace: dp.GetNamespace(), Name: dp.GetName()}
+		finding := dataplaneRule(status.Overall).Finding(ref, status.Details)
+		finding.Dataplane = &DataplaneStatus{
 			Name:      dp.GetName(),
 			Namespace: dp.GetNamespace(),
 			Status:    status.Overall,
 			Details:   status.Details,
+			Inbounds:  inbounds,
+			Outbounds: dataplaneOutbounds(dp),
 		}
 		result.Findings = append(result.Findings, finding)
+
+		if insights == nil {
+			continue
+		}
+		insight := insights[dataplaneKey(dp)]
+		finding.Dataplane.Insight = insight
+		if !isStale {
+			// De un Dataplane obsoleto solo interesa borrarlo.
+			result.Findings = append(result.Findings, insightFindings(ref, insight, now, window)...)
+		}
 	}
 
 	return result, nil
 }
 
+// dataplaneInsights devuelve los DataplaneInsight del mesh indexados por dataplaneKey. Devuelve
+// nil, y los hallazgos de conexión se omiten, si no hay ninguno: ocurre con exportaciones que no
+// los incluyen o si el CRD no está instalado, y marcar todos los proxies como no conectados
+// solo sería ruido.
+func dataplaneInsights(ctx context.Context, env *Env) (map[string]*DataplaneInsightStatus, error) {
+	items, err := env.Source.List(ctx, DataplaneInsightType, env.Namespace)
+	switch {
+	case IsNotInstalled(err):
+		return nil, fmt.Errorf("el CRD de DataplaneInsight no está instalado; no se analiza la conexión xDS de los proxies")
+	case err != nil:
+		return nil, fmt.Errorf("no se pudieron leer los DataplaneInsight: %w", err)
+	}
+	items = filterByMesh(items, env.Mesh)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no hay DataplaneInsights en el mesh; no se analiza la conexión xDS de los proxies")
+	}
+	insights := make(map[string]*DataplaneInsightStatus, len(items))
+	for _, item := range items {
+		insights[dataplaneKey(item)] = parseDataplaneInsight(item)
+	}
+	return insights, nil
+}
+
+// dataplaneKey identifica un Dataplane y su DataplaneInsight, que comparten nombre y namespace.
+func dataplaneKey(obj unstructured.Unstructured) string {
+	return meshOf(obj) + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// dataplaneRule devuelve la regla correspondiente a cada estado de un Dataplane.
+func dataplaneRule(status string) Rule {
+	switch status {
+	case "Online":
+		return RuleDataplaneOnline
+	case "Degraded":
+		return RuleDataplaneDegraded
+	case "Offline":
+		return RuleDataplaneOffline
+	case "Stale":
+		return RuleDataplaneStale
+	default:
+		return RuleDataplaneNoInbounds
+	}
+}
+
 // Estructura interna para el estado derivado de los inbounds
 type derivedStatus struct {
 	Overall string
 	Details string
 }
 
-func getDataplaneStatusFromInbounds(dp unstructured.Unstructured) (derivedStatus, []string) {
-	inbounds, found, err := unstructured.NestedSlice(dp.Object, "spec", "networking", "inbound")
-	if err != nil || !found || len(inbounds) == 0 {
+// getDataplaneStatusFromInbounds deriva el estado del Dataplane de la salud de sus inbounds y
+// los devuelve todos, para que el reporte muestre cuál falla. En los estados Degraded y Offline
+// los detalles nombran los puertos que no están 'ready'.
+func getDataplaneStatusFromInbounds(dp unstructured.Unstructured) (derivedStatus, []InboundStatus) {
+	items, found, err := unstructured.NestedSlice(dp.Object, "spec", "networking", "inbound")
+	if err != nil || !found || len(items) == 0 {
 		return derivedStatus{"Info", "Sin inbounds definidos"}, nil
 	}
 
-	totalInbounds := len(inbounds)
+	totalInbounds := len(items)
 	readyInbounds := 0
+	var inbounds []InboundStatus
 	var unhealthyDetails []string
 
-	for _, inboundItem := range inbounds {
+	for _, inboundItem := range items {
 		inboundMap, ok := inboundItem.(map[string]interface{})
 		if !ok {
 			continue
 		}
+		port, _, _ := unstructured.NestedInt64(inboundMap, "port")
+		tags, _, _ := unstructured.NestedStringMap(inboundMap, "tags")
 		isReady, readyFound, _ := unstructured.NestedBool(inboundMap, "health", "ready")
-		if readyFound && isReady {
+		inbound := InboundStatus{Port: port, Service: tags[serviceTag], Tags: tags, Ready: readyFound && isReady}
+		inbounds = append(inbounds, inbound)
+		if inbound.Ready {
 			readyInbounds++
 		} else {
-			port, _, _ := unstructured.NestedInt64(inboundMap, "port")
-			service, _, _ := unstructured.NestedString(inboundMap, "tags", "kuma.io/service")
-			unhealthyDetails = append(unhealthyDetails, fmt.Sprintf("Puerto %d (Servicio: %s) no está 'ready'", port, service))
+			unhealthyDetails = append(unhealthyDetails, fmt.Sprintf("puerto %d (%s)", port, orUnknown(inbound.Service)))
 		}
 	}
 
 	if readyInbounds == totalInbounds {
-		return derivedStatus{"Online", "Todos los inbounds 'ready'"}, nil
+		return derivedStatus{"Online", "Todos los inbounds 'ready'"}, inbounds
 	} else if readyInbounds > 0 {
-		return derivedStatus{"Degraded", fmt.Sprintf("%d de %d inbounds 'ready'", readyInbounds, totalInbounds)}, unhealthyDetails
+		return derivedStatus{"Degraded", fmt.Sprintf("%d de %d inbounds 'ready'; no 'ready': %s",
+			readyInbounds, totalInbounds, strings.Join(unhealthyDetails, ", "))}, inbounds
 	} else {
-		return derivedStatus{"Offline", "Ningún inbound está 'ready'"}, unhealthyDetails
+		return derivedStatus{"Offline", "Ningún inbound está 'ready'"}, inbounds
+	}
+}
+
+// dataplaneOutbounds devuelve los outbounds declarados en el Dataplane. Con proxy transparente
+// en Kubernetes el control plane los genera para todos los servicios alcanzables.
+func dataplaneOutbounds(dp unstructured.Unstructured) []OutboundStatus {
+	items, _, _ := unstructured.NestedSlice(dp.Object, "spec", "networking", "outbound")
+	var outbounds []OutboundStatus
+	for _, item := range items {
+		outboundMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		outbound := OutboundStatus{}
+		outbound.Address, _, _ = unstructured.NestedString(outboundMap, "address")
+		outbound.Port, _, _ = unstructured.NestedInt64(outboundMap, "port")
+		outbound.Tags, _, _ = unstructured.NestedStringMap(outboundMap, "tags")
+		if backendRef, found, _ := unstructured.NestedMap(outboundMap, "backendRef"); found {
+			ref := &OutboundBackendRef{}
+			ref.Kind, _, _ = unstructured.NestedString(backendRef, "kind")
+			ref.Name, _, _ = unstructured.NestedString(backendRef, "name")
+			ref.Port, _, _ = unstructured.NestedInt64(backendRef, "port")
+			ref.Labels, _, _ = unstructured.NestedStringMap(backendRef, "labels")
+			outbound.BackendRef = ref
+		}
+		outbounds = append(outbounds, outbound)
 	}
+	return outbounds
 }
@@ -0,0 +1,133 @@
+// pkg/analysis/discovery.go
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// kumaGroup es el grupo de API de los CRDs de Kuma.
+const kumaGroup = "kuma.io"
+
+// ErrDiscoveryUnsupported lo devuelven los orígenes que no pueden consultar qué recursos
+// sirve el servidor (manifiestos exportados, API del control plane en Universal).
+var ErrDiscoveryUnsupported = errors.New("el origen de datos no permite descubrir los CRDs instalados")
+
+// Discoverer es una interfaz opcional de Source para los orígenes que pueden consultar la
+// API de discovery de Kubernetes.
+type Discoverer interface {
+	// Discover devuelve los recursos que sirve el servidor para un grupo de API. Si el grupo
+	// no existe, devuelve un APIDiscovery sin versiones.
+	Discover(ctx context.Context, group string) (*APIDiscovery, error)
+}
+
+// APIDiscovery describe los recursos que sirve el servidor para un grupo de API.
+type APIDiscovery struct {
+	Group            string
+	PreferredVersion string
+	// Resources son los recursos servidos en cada versión, indexados por kind.
+	Resources map[string]map[string]DiscoveredResource
+}
+
+// DiscoveredResource es un recurso servido por el API server.
+type DiscoveredResource struct {
+	Name       string
+	Namespaced bool
+}
+
+// Installed indica si el grupo se sirve en alguna versión.
+func (d *APIDiscovery) Installed() bool {
+	return len(d.Resources) > 0
+}
+
+// ServedVersions devuelve, ordenadas, las versiones en las que se sirve un kind.
+func (d *APIDiscovery) ServedVersions(kind string) []string {
+	var versions []string
+	for version, resources := range d.Resources {
+		if _, ok := resources[kind]; ok {
+			versions = append(versions, version)
+		}
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Resolve devuelve el tipo de recurso tal y como lo sirve el servidor: en la versión
+// preferida del grupo si está disponible en ella o, si no, en la primera versión que lo
+// sirva. El segundo valor es false si el kind no se sirve en ninguna versión.
+func (d *APIDiscovery) Resolve(rt ResourceType) (ResourceType, bool) {
+	versions := d.ServedVersions(rt.Kind)
+	if len(versions) == 0 {
+		return rt, false
+	}
+	version := versions[0]
+	if _, ok := d.Resources[d.PreferredVersion][rt.Kind]; ok {
+		version = d.PreferredVersion
+	}
+	served := d.Resources[version][rt.Kind]
+	resolved := rt
+	resolved.GVR.Version = version
+	resolved.GVR.Resource = served.Name
+	resolved.Namespaced = served.Namespaced
+	return resolved, true
+}
+
+// NotInstalledError indica que el servidor no sirve un tipo de recurso: su CRD no está
+// instalado (o, en Universal, el control plane no conoce ese tipo).
+type NotInstalledError struct {
+	Type ResourceType
+}
+
+func (e *NotInstalledError) Error() string {
+	return fmt.Sprintf("el CRD %s (%s) no está instalado", e.Type.GVR.GroupResource(), e.Type.Kind)
+}
+
+// IsNotInstalled indica si err se debe a un tipo de recurso no instalado.
+func IsNotInstalled(err error) bool {
+	var notInstalled *NotInstalledError
+	return errors.As(err, &notInstalled)
+}
+
+// skipIfNotInstalled devuelve el resultado de un analizador omitido si err se debe a que
+// falta el CRD del que depende. El segundo valor es false para cualquier otro error.
+func skipIfNotInstalled(title string, err error) (*ValidationResult, bool) {
+	var notInstalled *NotInstalledError
+	if !errors.As(err, &notInstalled) {
+		return nil, false
+	}
+	return &ValidationResult{
+		Title:       title,
+		GeneratedAt: time.Now(),
+		Status:      StatusSkipped,
+		Reason:      notInstalled.Error(),
+		Findings:    []Finding{notInstalledFinding(notInstalled.Type)},
+	}, true
+}
+
+// notInstalledFinding es el hallazgo con el que se omiten los chequeos que dependen de un CRD
+// no instalado.
+func notInstalledFinding(rt ResourceType) Finding {
+	return RuleCRDNotInstalled.Finding(
+		crdRef(rt),
+		fmt.Sprintf("El CRD de %s no está instalado; se omiten los chequeos que dependen de él.", rt.Kind),
+	)
+}
+
+// crdRef construye la referencia al CRD de un tipo de recurso.
+func crdRef(rt ResourceType) ResourceRef {
+	return ResourceRef{Kind: "CustomResourceDefinition", Name: rt.GVR.GroupResource().String()}
+}
+
+// asNotInstalled convierte el NotFound de un List (el tipo de recurso no existe) en un
+// NotInstalledError, para los orígenes sin discovery.
+func asNotInstalled(rt ResourceType, err error) error {
+	if apierrors.IsNotFound(err) {
+		return &NotInstalledError{Type: rt}
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+// pkg/analysis/env.go
+package analysis
+
+import "time"
+
+// Env agrupa el origen de los recursos y el alcance sobre los que se ejecutan los análisis.
+// Se construye una vez a partir de los flags globales y se comparte entre todos los analizadores.
+type Env struct {
+	Source Source
+	// Namespace limita los Dataplanes (workloads) analizados. Las políticas de Kuma se leen
+	// siempre de todos los namespaces, porque normalmente viven en el namespace del control plane
+	// y se aplican a workloads de cualquier namespace. Vacío significa todos los namespaces.
+	Namespace string
+	// Meshes son los meshes solicitados con --mesh; vacío o "all" significa todos.
+	Meshes []string
+	// Mesh es el mesh que se está analizando en esta ejecución. Lo fija el runner para cada
+	// mesh resuelto; vacío significa que el analizador no filtra por mesh.
+	Mesh string
+	// Concurrency es el número máximo de análisis que se ejecutan en paralelo; 0 o 1 significa
+	// secuencial. Los analizadores solo leen de Source, así que pueden ejecutarse a la vez.
+	Concurrency int
+	// CertExpiryWindow es la antelación con la que se avisa de que el certificado mTLS de un
+	// Dataplane va a caducar; 0 significa DefaultCertExpiryWindow.
+	CertExpiryWindow time.Duration
+}
+
+// forMesh devuelve una copia del entorno acotada a un único mesh.
+func (e *Env) forMesh(mesh string) *Env {
+	scoped := *e
+	scoped.Mesh = mesh
+	return &scoped
+}
+
+// includesMesh indica si un mesh entra en el alcance del entorno: el mesh fijado por el runner
+// o, en los analizadores de clúster, los solicitados con --mesh.
+func (e *Env) includesMesh(mesh string) bool {
+	if e.Mesh != "" {
+		return e.Mesh == mesh
+	}
+	if len(e.Meshes) == 0 {
+		return true
+	}
+	for _, requested := range e.Meshes {
+		if requested == AllMeshes || requested == mesh {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,306 @@
+// pkg/analysis/explain.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Secciones de una política de las que sale la configuración efectiva.
+const (
+	SectionDefault = "default" // 'spec.default' (MeshTrace, MeshMetric...)
+	SectionTo      = "to"
+	SectionFrom    = "from"
+)
+
+// targetRefSpecificity ordena los kinds de targetRef de menos a más específico, igual que
+// el control plane al fusionar políticas.
+var targetRefSpecificity = map[string]int{
+	TargetMesh:              0,
+	TargetMeshSubset:        1,
+	TargetMeshGateway:       2,
+	TargetMeshService:       3,
+	TargetMeshServiceSubset: 4,
+}
+
+// DataplaneExplanation es la configuración efectiva de las políticas sobre un Dataplane.
+type DataplaneExplanation struct {
+	Dataplane   ResourceRef         `json:"dataplane"`
+	GeneratedAt time.Time           `json:"generatedAt"`
+	Gateway     bool                `json:"gateway"`
+	Services    []string            `json:"services"`
+	Policies    []PolicyExplanation `json:"policies"`
+	// Diagnostics son los tipos de política que no se pudieron leer; la explicación está
+	// incompleta para ellos.
+	Diagnostics []string `json:"diagnostics,omitempty"`
+}
+
+// PolicyExplanation agrupa las políticas de un tipo que se aplican al Dataplane.
+type PolicyExplanation struct {
+	Kind string `json:"kind"`
+	// Installed es false si el CRD del tipo no está instalado.
+	Installed bool `json:"installed"`
+	// Matches son las políticas que seleccionan el Dataplane, en orden de fusión: cada una
+	// sobrescribe a las anteriores.
+	Matches   []PolicyMatch   `json:"matches"`
+	Effective []EffectiveConf `json:"effective"`
+}
+
+// PolicyMatch es una política cuyo 'spec.targetRef' selecciona el Dataplane.
+type PolicyMatch struct {
+	Policy    ResourceRef `json:"policy"`
+	TargetRef TargetRef   `json:"targetRef"`
+	// Services son los servicios del Dataplane seleccionados por el targetRef.
+	Services []string `json:"services,omitempty"`
+}
+
+// EffectiveConf es la configuración resultante para una sección de la política y, en
+// 'to' y 'from', para un targetRef de destino u origen.
+type EffectiveConf struct {
+	Section   string                 `json:"section"`
+	TargetRef *TargetRef             `json:"targetRef,omitempty"`
+	Conf      map[string]interface{} `json:"conf"`
+	// Origins son las políticas que aportan a esta configuración, en orden de fusión.
+	Origins []string `json:"origins"`
+}
+
+// ExplainDataplane calcula, para cada tipo de política, qué políticas seleccionan el
+// Dataplane indicado y la configuración que resulta de fusionarlas.
+func ExplainDataplane(ctx context.Context, env *Env, namespace, name string) (*DataplaneExplanation, error) {
+	dataplanes, err := env.Source.List(ctx, DataplaneType, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error al listar Dataplanes: %w", err)
+	}
+	mesh := ""
+	for _, dp := range dataplanes {
+		if dp.GetNamespace() == namespace && dp.GetName() == name {
+			mesh = meshOf(dp)
+			break
+		}
+	}
+	if mesh == "" {
+		return nil, fmt.Errorf("no se encontró el Dataplane %s", ResourceRef{Namespace: namespace, Name: name})
+	}
+
+	// El resolver abarca todo el mesh: para fusionar las entradas 'to' y 'from' hay que saber
+	// qué servicios de otros namespaces seleccionan.
+	scoped := env.forMesh(mesh)
+	scoped.Namespace = ""
+	resolver, err := NewTargetResolver(ctx, scoped)
+	if err != nil {
+		return nil, err
+	}
+	proxy, _ := resolver.Proxy(namespace, name)
+
+	explanation := &DataplaneExplanation{
+		Dataplane:   ResourceRef{Kind: "Dataplane", Mesh: mesh, Namespace: namespace, Name: name},
+		GeneratedAt: time.Now(),
+		Gateway:     proxy.IsGateway(),
+		Services:    proxy.Services(),
+	}
+	for _, policyType := range PolicyTypes {
+		policies, err := env.Source.List(ctx, policyType, "")
+		switch {
+		case IsNotInstalled(err):
+			explanation.Policies = append(explanation.Policies, PolicyExplanation{Kind: policyType.Kind})
+			continue
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			explanation.Diagnostics = append(explanation.Diagnostics,
+				fmt.Sprintf("no se pudieron leer las políticas %s: %v", policyType.Kind, err))
+			continue
+		}
+		explanation.Policies = append(explanation.Policies,
+			explainPolicyType(resolver, proxy, policyType, filterByMesh(policies, mesh)))
+	}
+	return explanation, nil
+}
+
+// matchedPolicy es una política que selecciona el Dataplane, con su targetRef ya parseado.
+type matchedPolicy struct {
+	policy    unstructured.Unstructured
+	targetRef TargetRef
+}
+
+// explainPolicyType ordena las políticas de un tipo que seleccionan el Dataplane y fusiona
+// su configuración.
+func explainPolicyType(resolver *TargetResolver, proxy Proxy, policyType ResourceType, policies []unstructured.Unstructured) PolicyExplanation {
+	var matched []matchedPolicy
+	for _, policy := range policies {
+		ref := policyTargetRef(policy)
+		if resolver.SelectsProxy(ref, proxy) {
+			matched = append(matched, matchedPolicy{policy: policy, targetRef: ref})
+		}
+	}
+	sortByMergeOrder(matched)
+
+	explanation := PolicyExplanation{
+		Kind:      policyType.Kind,
+		Installed: true,
+		Matches:   []PolicyMatch{},
+		Effective: []EffectiveConf{},
+	}
+	for _, m := range matched {
+		explanation.Matches = append(explanation.Matches, PolicyMatch{
+			Policy:    policyRef(m.policy),
+			TargetRef: m.targetRef,
+			Services:  resolver.SelectProxyServices(m.targetRef, proxy),
+		})
+	}
+
+	if conf := mergeDefaults(matched); conf != nil {
+		explanation.Effective = append(explanation.Effective, *conf)
+	}
+	for _, section := range []string{SectionTo, SectionFrom} {
+		explanation.Effective = append(explanation.Effective, mergeSection(resolver, matched, section)...)
+	}
+	return explanation
+}
+
+// sortByMergeOrder ordena las políticas como las fusiona Kuma: primero por la especificidad
+// del kind de 'spec.targetRef' (la más específica gana) y, a igualdad de kind, por nombre en
+// orden inverso, de forma que la de nombre lexicográficamente menor se aplica la última y gana.
+func sortByMergeOrder(matched []matchedPolicy) {
+	sort.SliceStable(matched, func(i, j int) bool {
+		si, sj := targetRefSpecificity[matched[i].targetRef.Kind], targetRefSpecificity[matched[j].targetRef.Kind]
+		if si != sj {
+			return si < sj
+		}
+		return matched[i].policy.GetName() > matched[j].policy.GetName()
+	})
+}
+
+// orderedRules devuelve las entradas 'to' o 'from' de una política ordenadas de menos a más
+// específicas según el kind de su targetRef, respetando el orden original a igualdad de kind.
+// Es el orden en que Kuma las fusiona dentro de una misma política.
+func orderedRules(policy unstructured.Unstructured, section string) []PolicyRule {
+	rules := policyRules(policy, section)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return targetRefSpecificity[rules[i].TargetRef.Kind] < targetRefSpecificity[rules[j].TargetRef.Kind]
+	})
+	return rules
+}
+
+// mergeDefaults fusiona el 'spec.default' de primer nivel. Devuelve nil si ninguna política
+// lo define.
+func mergeDefaults(matched []matchedPolicy) *EffectiveConf {
+	var effective *EffectiveConf
+	for _, m := range matched {
+		conf, found, _ := unstructured.NestedMap(m.policy.Object, "spec", "default")
+		if !found {
+			continue
+		}
+		if effective == nil {
+			effective = &EffectiveConf{Section: SectionDefault, Conf: map[string]interface{}{}}
+		}
+		mergeConf(effective.Conf, conf)
+		effective.Origins = append(effective.Origins, policyRef(m.policy).String())
+	}
+	return effective
+}
+
+// mergeSection fusiona las entradas de 'spec.to' o 'spec.from', una configuración por cada
+// targetRef distinto. Una entrada se aplica también a los targetRef más específicos que
+// abarca (ver coversTargetRef): las 'kind: Mesh' a todos, una MeshService a sus
+// MeshServiceSubset, una MeshSubset a los servicios cuyos inbounds tienen sus etiquetas...
+// Se respeta el orden de las políticas y, dentro de cada una, el de orderedRules.
+func mergeSection(resolver *TargetResolver, matched []matchedPolicy, section string) []EffectiveConf {
+	type entry struct {
+		origin string
+		rule   PolicyRule
+	}
+	var entries []entry
+	refs := make(map[string]TargetRef)
+	for _, m := range matched {
+		for _, rule := range orderedRules(m.policy, section) {
+			entries = append(entries, entry{origin: policyRef(m.policy).String(), rule: rule})
+			refs[rule.TargetRef.key()] = rule.TargetRef
+		}
+	}
+
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		si, sj := targetRefSpecificity[refs[keys[i]].Kind], targetRefSpecificity[refs[keys[j]].Kind]
+		if si != sj {
+			return si < sj
+		}
+		return keys[i] < keys[j]
+	})
+
+	inbounds := make(map[string]map[string]bool)
+	selected := func(ref TargetRef) map[string]bool {
+		if _, ok := inbounds[ref.key()]; !ok {
+			inbounds[ref.key()] = resolver.selectedInbounds(ref)
+		}
+		return inbounds[ref.key()]
+	}
+
+	var effective []EffectiveConf
+	for _, key := range keys {
+		ref := refs[key]
+		conf := EffectiveConf{Section: section, TargetRef: &ref, Conf: map[string]interface{}{}}
+		for _, e := range entries {
+			if !coversTargetRef(e.rule.TargetRef, ref, selected) {
+				continue
+			}
+			mergeConf(conf.Conf, e.rule.Default)
+			if n := len(conf.Origins); n == 0 || conf.Origins[n-1] != e.origin {
+				conf.Origins = append(conf.Origins, e.origin)
+			}
+		}
+		effective = append(effective, conf)
+	}
+	return effective
+}
+
+// coversTargetRef indica si una entrada con el targetRef entry se aplica al destino u origen
+// ref: si es 'kind: Mesh', si es el mismo targetRef o si selecciona todos los inbounds que
+// selecciona ref. Un ref que no selecciona ningún inbound del mesh (p. ej. un servicio sin
+// Dataplanes) solo lo cubren las entradas Mesh y las idénticas.
+func coversTargetRef(entry, ref TargetRef, selected func(TargetRef) map[string]bool) bool {
+	if entry.Kind == TargetMesh || entry.key() == ref.key() {
+		return true
+	}
+	inbounds := selected(ref)
+	if len(inbounds) == 0 {
+		return false
+	}
+	covered := selected(entry)
+	for inbound := range inbounds {
+		if !covered[inbound] {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeConf fusiona src sobre dst como el control plane: los objetos se fusionan campo a
+// campo y el resto de valores (incluidas las listas) se reemplazan, salvo las
+// appendModifications de MeshProxyPatch, que se acumulan.
+func mergeConf(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, isMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if isMap && dstIsMap {
+			mergeConf(dstMap, srcMap)
+			continue
+		}
+		srcList, isList := value.([]interface{})
+		dstList, dstIsList := dst[key].([]interface{})
+		if key == "appendModifications" && isList && dstIsList {
+			dst[key] = append(dstList, runtime.DeepCopyJSONValue(srcList).([]interface{})...)
+			continue
+		}
+		dst[key] = runtime.DeepCopyJSONValue(value)
+	}
+}
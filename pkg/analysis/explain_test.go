@@ -0,0 +1,224 @@
+// pkg/analysis/explain_test.go
+package analysis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// mergePolicies son tres MeshTimeout que se fusionan en el orden b, a (mismo kind, gana el
+// nombre menor) y z (MeshService, más específica).
+const mergePolicies = `
+apiVersion: kuma.io/v1alpha1
+kind: MeshTimeout
+metadata: {name: z, namespace: kuma-system, labels: {kuma.io/mesh: default}}
+spec:
+  targetRef: {kind: MeshService, name: web}
+  to:
+  - targetRef: {kind: Mesh}
+    default: {connectionTimeout: 7s}
+---
+apiVersion: kuma.io/v1alpha1
+kind: MeshTimeout
+metadata: {name: a, namespace: kuma-system, labels: {kuma.io/mesh: default}}
+spec:
+  targetRef: {kind: Mesh}
+  to:
+  - targetRef: {kind: MeshService, name: backend}
+    default: {http: {requestTimeout: 2s}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: MeshTimeout
+metadata: {name: b, namespace: kuma-system, labels: {kuma.io/mesh: default}}
+spec:
+  targetRef: {kind: Mesh}
+  to:
+  - targetRef: {kind: MeshService, name: backend}
+    default: {idleTimeout: 10s, http: {requestTimeout: 1s, streamIdleTimeout: 30s}}
+  - targetRef: {kind: Mesh}
+    default: {connectionTimeout: 5s, idleTimeout: 1s}
+`
+
+// mergeTestPolicies devuelve las políticas de mergePolicies en el orden de fusión.
+func mergeTestPolicies(t *testing.T) []matchedPolicy {
+	t.Helper()
+	policies, _ := newFakeSource(t, mergePolicies).List(context.Background(), MeshTimeoutType, "")
+	var matched []matchedPolicy
+	for _, policy := range policies {
+		matched = append(matched, matchedPolicy{policy: policy, targetRef: policyTargetRef(policy)})
+	}
+	sortByMergeOrder(matched)
+	return matched
+}
+
+func TestSortByMergeOrder(t *testing.T) {
+	var names []string
+	for _, m := range mergeTestPolicies(t) {
+		names = append(names, m.policy.GetName())
+	}
+	if want := []string{"b", "a", "z"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("orden de fusión = %v, se esperaba %v", names, want)
+	}
+}
+
+// newTestResolver crea un TargetResolver del mesh default con los Dataplanes de manifests.
+func newTestResolver(t *testing.T, manifests string) *TargetResolver {
+	t.Helper()
+	resolver, err := NewTargetResolver(context.Background(), &Env{Source: newFakeSource(t, manifests), Mesh: "default"})
+	if err != nil {
+		t.Fatalf("NewTargetResolver: %v", err)
+	}
+	return resolver
+}
+
+func TestMergeSection(t *testing.T) {
+	resolver := newTestResolver(t, "")
+	effective := mergeSection(resolver, mergeTestPolicies(t), SectionTo)
+
+	want := []EffectiveConf{
+		{
+			Section:   SectionTo,
+			TargetRef: &TargetRef{Kind: TargetMesh},
+			Conf:      map[string]interface{}{"connectionTimeout": "7s", "idleTimeout": "1s"},
+			Origins:   []string{"MeshTimeout/kuma-system/b", "MeshTimeout/kuma-system/z"},
+		},
+		{
+			// Las entradas 'kind: Mesh' se aplican también a MeshService/backend; dentro de b,
+			// la entrada Mesh va antes que la de MeshService aunque se declare después.
+			Section:   SectionTo,
+			TargetRef: &TargetRef{Kind: TargetMeshService, Name: "backend", policyNamespace: "kuma-system"},
+			Conf: map[string]interface{}{
+				"connectionTimeout": "7s",
+				"idleTimeout":       "10s",
+				"http":              map[string]interface{}{"requestTimeout": "2s", "streamIdleTimeout": "30s"},
+			},
+			Origins: []string{"MeshTimeout/kuma-system/b", "MeshTimeout/kuma-system/a", "MeshTimeout/kuma-system/z"},
+		},
+	}
+	if !reflect.DeepEqual(effective, want) {
+		t.Errorf("mergeSection =\n%#v\nse esperaba\n%#v", effective, want)
+	}
+
+	if from := mergeSection(resolver, mergeTestPolicies(t), SectionFrom); len(from) != 0 {
+		t.Errorf("mergeSection(from) = %v, se esperaba vacío", from)
+	}
+}
+
+// subsetResources son dos réplicas de backend (v1 y v2), los servicios web y admin del equipo
+// front y una MeshTimeout con entradas para todo backend, para su subconjunto v2, para web y
+// para el equipo front.
+const subsetResources = `
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: backend-1, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 3001, tags: {kuma.io/service: backend, version: v1}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: backend-2, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 3001, tags: {kuma.io/service: backend, version: v2}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: web-1, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 80, tags: {kuma.io/service: web, team: front}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: admin-1, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 8080, tags: {kuma.io/service: admin, team: front}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: MeshTimeout
+metadata: {name: subsets, labels: {kuma.io/mesh: default}}
+spec:
+  targetRef: {kind: Mesh}
+  from:
+  - targetRef: {kind: MeshServiceSubset, name: backend, tags: {version: v2}}
+    default: {http: {requestTimeout: 2s}}
+  - targetRef: {kind: MeshService, name: backend}
+    default: {idleTimeout: 10s, http: {requestTimeout: 1s}}
+  - targetRef: {kind: MeshService, name: web}
+    default: {connectionTimeout: 3s}
+  - targetRef: {kind: MeshSubset, tags: {team: front}}
+    default: {idleTimeout: 20s}
+`
+
+func TestMergeSectionSubsets(t *testing.T) {
+	source := newFakeSource(t, subsetResources)
+	policies, _ := source.List(context.Background(), MeshTimeoutType, "")
+	matched := []matchedPolicy{{policy: policies[0], targetRef: policyTargetRef(policies[0])}}
+
+	effective := mergeSection(newTestResolver(t, subsetResources), matched, SectionFrom)
+
+	got := make(map[string]map[string]interface{})
+	for _, conf := range effective {
+		got[conf.TargetRef.String()] = conf.Conf
+	}
+	want := map[string]map[string]interface{}{
+		// La MeshSubset del equipo front abarca a web, pero no a la inversa.
+		"MeshSubset[team=front]": {"idleTimeout": "20s"},
+		"MeshService/web":        {"idleTimeout": "20s", "connectionTimeout": "3s"},
+		// La entrada MeshService de backend se aplica también a su subconjunto v2, que es más
+		// específico y se fusiona después.
+		"MeshService/backend":                   {"idleTimeout": "10s", "http": map[string]interface{}{"requestTimeout": "1s"}},
+		"MeshServiceSubset/backend[version=v2]": {"idleTimeout": "10s", "http": map[string]interface{}{"requestTimeout": "2s"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeSection =\n%v\nse esperaba\n%v", got, want)
+	}
+}
+
+func TestMergeConf(t *testing.T) {
+	tests := []struct {
+		name string
+		dst  map[string]interface{}
+		src  map[string]interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "los objetos se fusionan campo a campo",
+			dst:  map[string]interface{}{"http": map[string]interface{}{"requestTimeout": "1s", "maxStreamDuration": "5s"}},
+			src:  map[string]interface{}{"http": map[string]interface{}{"requestTimeout": "2s"}},
+			want: map[string]interface{}{"http": map[string]interface{}{"requestTimeout": "2s", "maxStreamDuration": "5s"}},
+		},
+		{
+			name: "las listas se reemplazan",
+			dst:  map[string]interface{}{"backends": []interface{}{"a", "b"}},
+			src:  map[string]interface{}{"backends": []interface{}{"c"}},
+			want: map[string]interface{}{"backends": []interface{}{"c"}},
+		},
+		{
+			name: "un valor reemplaza a un objeto",
+			dst:  map[string]interface{}{"tcp": map[string]interface{}{"maxConnectAttempt": int64(3)}},
+			src:  map[string]interface{}{"tcp": nil},
+			want: map[string]interface{}{"tcp": nil},
+		},
+		{
+			name: "appendModifications se acumulan",
+			dst:  map[string]interface{}{"appendModifications": []interface{}{"a"}},
+			src:  map[string]interface{}{"appendModifications": []interface{}{"b"}},
+			want: map[string]interface{}{"appendModifications": []interface{}{"a", "b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mergeConf(tt.dst, tt.src)
+			if !reflect.DeepEqual(tt.dst, tt.want) {
+				t.Errorf("mergeConf = %v, se esperaba %v", tt.dst, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeConfCopiesSource(t *testing.T) {
+	src := map[string]interface{}{"http": map[string]interface{}{"requestTimeout": "1s"}}
+	dst := map[string]interface{}{}
+	mergeConf(dst, src)
+	mergeConf(dst, map[string]interface{}{"http": map[string]interface{}{"requestTimeout": "2s"}})
+
+	if got := src["http"].(map[string]interface{})["requestTimeout"]; got != "1s" {
+		t.Errorf("mergeConf modificó la política de origen: requestTimeout = %v", got)
+	}
+}
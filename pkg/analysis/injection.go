@@ -0,0 +1,139 @@
+// pkg/analysis/injection.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func init() {
+	Register(NewClusterAnalyzer("sidecar-injection", "Cobertura de Inyección de Sidecars", CategoryDataplanes, AnalyzeSidecarInjection, "injection"))
+}
+
+const sidecarInjectionTitle = "Análisis de Cobertura de Inyección de Sidecars"
+
+const (
+	// sidecarInjectionLabel activa la inyección en un namespace o la desactiva en un pod. Kuma
+	// acepta también la anotación del mismo nombre, que era la forma anterior.
+	sidecarInjectionLabel = "kuma.io/sidecar-injection"
+	sidecarContainer      = "kuma-sidecar"
+)
+
+// AnalyzeSidecarInjection revisa los pods de los namespaces con la inyección activada, para
+// encontrar lo que AnalyzeDataplanes no puede ver: pods que se quedaron fuera del mesh (sin el
+// contenedor kuma-sidecar) y pods que la desactivan explícitamente. Los Dataplanes cuyo pod ya
+// no existe los informa AnalyzeDataplanes como Stale (ver staleDataplanes).
+//
+// Es un análisis de clúster: revisa los pods de todos los meshes solicitados con --mesh en
+// una sola pasada.
+func AnalyzeSidecarInjection(ctx context.Context, env *Env) (*ValidationResult, error) {
+	result := &ValidationResult{Title: sidecarInjectionTitle, GeneratedAt: time.Now()}
+
+	namespaces, err := env.Source.List(ctx, NamespaceType, "")
+	if err != nil {
+		if isMissing(err) {
+			result.Status, result.Reason = StatusSkipped, "no hay Namespaces de Kubernetes en el origen de datos (Universal o exportación sin namespaces)"
+			return result, nil
+		}
+		return nil, fmt.Errorf("error al listar Namespaces: %w", err)
+	}
+
+	injected := make(map[string]unstructured.Unstructured)
+	for _, ns := range namespaces {
+		if env.Namespace != "" && ns.GetName() != env.Namespace {
+			continue
+		}
+		if injectionEnabled(ns) {
+			injected[ns.GetName()] = ns
+		}
+	}
+
+	total, covered := 0, 0
+	for _, name := range sortedKeys(injected) {
+		ns := injected[name]
+		pods, err := env.Source.List(ctx, PodType, name)
+		if err != nil {
+			result.Diagnostics = append(result.Diagnostics, fmt.Sprintf("namespace %s: %s", name, unavailableDiagnostic(PodType, err)))
+			continue
+		}
+		for _, pod := range pods {
+			if podFinished(pod) || !env.includesMesh(podMesh(pod, ns)) {
+				continue
+			}
+			total++
+			ref := ResourceRef{Kind: "Pod", Mesh: podMesh(pod, ns), Namespace: pod.GetNamespace(), Name: pod.GetName()}
+			switch {
+			case injectionDisabled(pod):
+				result.Findings = append(result.Findings, RulePodInjectionDisabled.Finding(ref, fmt.Sprintf(
+					"El pod desactiva la inyección con %s aunque su namespace la tiene activada: su tráfico no pasa por el mesh.", sidecarInjectionLabel)))
+			case !hasSidecar(pod):
+				result.Findings = append(result.Findings, RulePodWithoutSidecar.Finding(ref,
+					"El pod no tiene el contenedor kuma-sidecar aunque su namespace tiene la inyección activada: se creó antes de activarla o cuando el webhook no respondía."))
+			default:
+				covered++
+			}
+		}
+	}
+
+	clusterRef := ResourceRef{Kind: "Namespace", Name: "*"}
+	if len(injected) == 0 {
+		result.Findings = append(result.Findings, RuleNoInjectedNamespaces.Finding(clusterRef, fmt.Sprintf(
+			"Ningún namespace tiene la etiqueta %s=enabled.", sidecarInjectionLabel)))
+	} else {
+		result.Findings = append(result.Findings, RuleInjectionCoverage.Finding(clusterRef, fmt.Sprintf(
+			"%d de %d pods de los %d namespaces con la inyección activada tienen sidecar.", covered, total, len(injected))))
+	}
+	return result, nil
+}
+
+// injectionEnabled indica si un namespace tiene la inyección de sidecars activada.
+func injectionEnabled(ns unstructured.Unstructured) bool {
+	value := ns.GetLabels()[sidecarInjectionLabel]
+	if value == "" {
+		value = ns.GetAnnotations()[sidecarInjectionLabel]
+	}
+	return value == "enabled" || value == "true"
+}
+
+// injectionDisabled indica si un pod desactiva explícitamente la inyección.
+func injectionDisabled(pod unstructured.Unstructured) bool {
+	value := pod.GetLabels()[sidecarInjectionLabel]
+	if value == "" {
+		value = pod.GetAnnotations()[sidecarInjectionLabel]
+	}
+	return value == "disabled" || value == "false"
+}
+
+// hasSidecar indica si el pod tiene el contenedor kuma-sidecar, como contenedor normal o como
+// sidecar nativo (init container con restartPolicy: Always).
+func hasSidecar(pod unstructured.Unstructured) bool {
+	for _, field := range []string{"containers", "initContainers"} {
+		containers, _, _ := unstructured.NestedSlice(pod.Object, "spec", field)
+		for _, item := range containers {
+			if container, ok := item.(map[string]interface{}); ok && container["name"] == sidecarContainer {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// podFinished indica si el pod ya terminó (p. ej. el de un Job); Kuma borra su Dataplane.
+func podFinished(pod unstructured.Unstructured) bool {
+	phase, _, _ := unstructured.NestedString(pod.Object, "status", "phase")
+	return phase == "Succeeded" || phase == "Failed"
+}
+
+// podMesh devuelve el mesh al que se une un pod: el que indica el propio pod o su namespace
+// con kuma.io/mesh (etiqueta o anotación) o, si no, el mesh por defecto.
+func podMesh(pod, ns unstructured.Unstructured) string {
+	for _, values := range []map[string]string{pod.GetLabels(), pod.GetAnnotations(), ns.GetLabels(), ns.GetAnnotations()} {
+		if mesh := values[meshLabel]; mesh != "" {
+			return mesh
+		}
+	}
+	return defaultMesh
+}
@@ -0,0 +1,123 @@
+// pkg/analysis/injection_test.go
+package analysis
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// injectionNamespaces son demo (inyección por etiqueta), legacy (por la anotación anterior,
+// en el mesh other) y plain (sin inyección).
+const injectionNamespaces = `
+apiVersion: v1
+kind: Namespace
+metadata: {name: demo, labels: {kuma.io/sidecar-injection: enabled}}
+---
+apiVersion: v1
+kind: Namespace
+metadata: {name: legacy, annotations: {kuma.io/sidecar-injection: "true", kuma.io/mesh: other}}
+---
+apiVersion: v1
+kind: Namespace
+metadata: {name: plain}
+`
+
+// injectionPod devuelve un pod con los metadatos y los contenedores indicados.
+func injectionPod(metadata, spec string) string {
+	return "---\napiVersion: v1\nkind: Pod\nmetadata: " + metadata + "\nspec: " + spec + "\n"
+}
+
+const (
+	withSidecar       = `{containers: [{name: app}, {name: kuma-sidecar}]}`
+	withNativeSidecar = `{initContainers: [{name: kuma-sidecar, restartPolicy: Always}], containers: [{name: app}]}`
+	withoutSidecar    = `{containers: [{name: app}]}`
+)
+
+func TestAnalyzeSidecarInjection(t *testing.T) {
+	tests := []struct {
+		name     string
+		pods     string
+		meshes   []string
+		findings []string
+		coverage string
+	}{
+		{
+			name: "pods con sidecar normal y nativo",
+			pods: injectionPod(`{name: web, namespace: demo}`, withSidecar) +
+				injectionPod(`{name: api, namespace: legacy}`, withNativeSidecar),
+			findings: []string{RuleInjectionCoverage.ID},
+			coverage: "2 de 2 pods de los 2 namespaces",
+		},
+		{
+			name:     "pod sin sidecar",
+			pods:     injectionPod(`{name: web, namespace: demo}`, withoutSidecar),
+			findings: []string{RulePodWithoutSidecar.ID, RuleInjectionCoverage.ID},
+			coverage: "0 de 1 pods",
+		},
+		{
+			name:     "pod que desactiva la inyección",
+			pods:     injectionPod(`{name: web, namespace: demo, labels: {kuma.io/sidecar-injection: disabled}}`, withoutSidecar),
+			findings: []string{RulePodInjectionDisabled.ID, RuleInjectionCoverage.ID},
+			coverage: "0 de 1 pods",
+		},
+		{
+			name: "se ignoran los pods terminados y los de namespaces sin inyección",
+			pods: injectionPod(`{name: job, namespace: demo}`, withoutSidecar+"\nstatus: {phase: Succeeded}") +
+				injectionPod(`{name: web, namespace: plain}`, withoutSidecar),
+			findings: []string{RuleInjectionCoverage.ID},
+			coverage: "0 de 0 pods",
+		},
+		{
+			// El pod de legacy se une al mesh other por la anotación de su namespace.
+			name: "solo los meshes solicitados",
+			pods: injectionPod(`{name: web, namespace: demo}`, withSidecar) +
+				injectionPod(`{name: api, namespace: legacy}`, withoutSidecar),
+			meshes:   []string{"default"},
+			findings: []string{RuleInjectionCoverage.ID},
+			coverage: "1 de 1 pods",
+		},
+		{
+			name:     "el pod elige su mesh",
+			pods:     injectionPod(`{name: web, namespace: legacy, labels: {kuma.io/mesh: default}}`, withoutSidecar),
+			meshes:   []string{"default"},
+			findings: []string{RulePodWithoutSidecar.ID, RuleInjectionCoverage.ID},
+			coverage: "0 de 1 pods",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &Env{Source: newFakeSource(t, injectionNamespaces+tt.pods), Meshes: tt.meshes}
+			result, err := AnalyzeSidecarInjection(context.Background(), env)
+			if err != nil {
+				t.Fatalf("AnalyzeSidecarInjection: %v", err)
+			}
+			var ids []string
+			for _, finding := range result.Findings {
+				ids = append(ids, finding.RuleID)
+			}
+			if !reflect.DeepEqual(ids, tt.findings) {
+				t.Errorf("hallazgos = %v, se esperaba %v", ids, tt.findings)
+			}
+			if last := result.Findings[len(result.Findings)-1]; !strings.Contains(last.Message, tt.coverage) {
+				t.Errorf("cobertura = %q, se esperaba que incluyera %q", last.Message, tt.coverage)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSidecarInjectionWithoutInjectedNamespaces(t *testing.T) {
+	env := &Env{Source: newFakeSource(t, `
+apiVersion: v1
+kind: Namespace
+metadata: {name: plain}
+`)}
+	result, err := AnalyzeSidecarInjection(context.Background(), env)
+	if err != nil {
+		t.Fatalf("AnalyzeSidecarInjection: %v", err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].RuleID != RuleNoInjectedNamespaces.ID {
+		t.Errorf("hallazgos = %+v, se esperaba solo %s", result.Findings, RuleNoInjectedNamespaces.ID)
+	}
+}
@@ -0,0 +1,152 @@
+// pkg/analysis/insights.go
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultCertExpiryWindow es la antelación por defecto del aviso de caducidad de certificados.
+// Kuma emite certificados de 24h y los rota al consumir 4/5 de su vida, así que a un proxy sano
+// nunca le quedan menos de ~4,8h.
+const DefaultCertExpiryWindow = 4 * time.Hour
+
+// DataplaneInsightStatus resume el DataplaneInsight de un proxy: su suscripción xDS actual, las
+// versiones que reporta y el estado de su certificado mTLS.
+type DataplaneInsightStatus struct {
+	Connected            bool       `json:"connected"`
+	ControlPlaneInstance string     `json:"controlPlaneInstance,omitempty"`
+	ConnectTime          *time.Time `json:"connectTime,omitempty"`
+	DisconnectTime       *time.Time `json:"disconnectTime,omitempty"`
+	LastUpdateTime       *time.Time `json:"lastUpdateTime,omitempty"`
+	KumaDpVersion        string     `json:"kumaDpVersion,omitempty"`
+	EnvoyVersion         string     `json:"envoyVersion,omitempty"`
+	// Incompatibilities son las incompatibilidades de versión que reporta el propio proxy.
+	Incompatibilities []string `json:"incompatibilities,omitempty"`
+	// StaleSubscriptions son las instancias del control plane con una suscripción anterior a la
+	// actual que nunca se cerró.
+	StaleSubscriptions       []string   `json:"staleSubscriptions,omitempty"`
+	CertificateExpiration    *time.Time `json:"certificateExpiration,omitempty"`
+	CertificateRegenerations int64      `json:"certificateRegenerations,omitempty"`
+	subscriptions            int
+}
+
+// parseDataplaneInsight lee el 'status' de un DataplaneInsight. Kuma añade una suscripción por
+// cada conexión xDS y conserva las últimas; la vigente es la última de la lista.
+func parseDataplaneInsight(insight unstructured.Unstructured) *DataplaneInsightStatus {
+	status := &DataplaneInsightStatus{}
+	subscriptions, _, _ := unstructured.NestedSlice(insight.Object, "status", "subscriptions")
+	status.subscriptions = len(subscriptions)
+	for i, item := range subscriptions {
+		subscription, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		instance, _, _ := unstructured.NestedString(subscription, "controlPlaneInstanceId")
+		disconnect := nestedTime(subscription, "disconnectTime")
+		if i < len(subscriptions)-1 {
+			if disconnect == nil {
+				status.StaleSubscriptions = append(status.StaleSubscriptions, instance)
+			}
+			continue
+		}
+
+		status.Connected = disconnect == nil
+		status.ControlPlaneInstance = instance
+		status.ConnectTime = nestedTime(subscription, "connectTime")
+		status.DisconnectTime = disconnect
+		status.LastUpdateTime = nestedTime(subscription, "status", "lastUpdateTime")
+		status.KumaDpVersion, _, _ = unstructured.NestedString(subscription, "version", "kumaDp", "version")
+		status.EnvoyVersion, _, _ = unstructured.NestedString(subscription, "version", "envoy", "version")
+		// Solo un 'false' explícito es una incompatibilidad: las versiones antiguas de kuma-dp
+		// no informan de estos campos.
+		if compatible, found, _ := unstructured.NestedBool(subscription, "version", "kumaDp", "kumaCpCompatible"); found && !compatible {
+			status.Incompatibilities = append(status.Incompatibilities,
+				fmt.Sprintf("kuma-dp %s no es compatible con el control plane", orUnknown(status.KumaDpVersion)))
+		}
+		if compatible, found, _ := unstructured.NestedBool(subscription, "version", "envoy", "kumaDpCompatible"); found && !compatible {
+			status.Incompatibilities = append(status.Incompatibilities,
+				fmt.Sprintf("Envoy %s no es compatible con kuma-dp %s", orUnknown(status.EnvoyVersion), orUnknown(status.KumaDpVersion)))
+		}
+	}
+
+	status.CertificateExpiration = nestedTime(insight.Object, "status", "mTLS", "certificateExpirationTime")
+	status.CertificateRegenerations, _, _ = unstructured.NestedInt64(insight.Object, "status", "mTLS", "certificateRegenerations")
+	return status
+}
+
+// insightFindings devuelve los hallazgos de la conexión xDS, las versiones y el certificado de
+// un Dataplane. insight es nil si el control plane no ha publicado su DataplaneInsight.
+func insightFindings(ref ResourceRef, insight *DataplaneInsightStatus, now time.Time, window time.Duration) []Finding {
+	var findings []Finding
+	add := func(rule Rule, status, message string) {
+		finding := rule.Finding(ref, message)
+		finding.Dataplane = &DataplaneStatus{Name: ref.Name, Namespace: ref.Namespace, Status: status, Details: message}
+		findings = append(findings, finding)
+	}
+
+	if insight == nil || insight.subscriptions == 0 {
+		add(RuleDataplaneNeverConnected, "NeverConnected", "El proxy nunca se ha conectado al control plane (no hay suscripciones xDS).")
+		return findings
+	}
+
+	if !insight.Connected {
+		add(RuleDataplaneDisconnected, "Disconnected", fmt.Sprintf(
+			"Desconectado del control plane desde %s (última instancia: %s).", formatTime(insight.DisconnectTime), orUnknown(insight.ControlPlaneInstance),
+		))
+	}
+	if len(insight.StaleSubscriptions) > 0 {
+		add(RuleDataplaneStaleSubscription, "StaleSubscription", fmt.Sprintf(
+			"%d suscripción(es) xDS anteriores nunca se cerraron (instancias: %s).", len(insight.StaleSubscriptions), strings.Join(insight.StaleSubscriptions, ", "),
+		))
+	}
+
+	if expiration := insight.CertificateExpiration; expiration != nil {
+		switch {
+		case !expiration.After(now):
+			add(RuleDataplaneCertExpired, "CertExpired", fmt.Sprintf(
+				"El certificado mTLS caducó el %s (%d rotaciones).", formatTime(expiration), insight.CertificateRegenerations,
+			))
+		case expiration.Sub(now) <= window:
+			add(RuleDataplaneCertExpiring, "CertExpiring", fmt.Sprintf(
+				"El certificado mTLS caduca en %s (%s; %d rotaciones).", expiration.Sub(now).Round(time.Minute), formatTime(expiration), insight.CertificateRegenerations,
+			))
+		}
+	}
+
+	if len(insight.Incompatibilities) > 0 {
+		add(RuleDataplaneVersionIncompatible, "Incompatible", strings.Join(insight.Incompatibilities, "; ")+".")
+	}
+	return findings
+}
+
+// nestedTime lee una marca de tiempo RFC 3339, el formato en que Kuma serializa los
+// google.protobuf.Timestamp.
+func nestedTime(obj map[string]interface{}, fields ...string) *time.Time {
+	value, found, _ := unstructured.NestedString(obj, fields...)
+	if !found || value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "fecha desconocida"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "desconocida"
+	}
+	return value
+}
@@ -0,0 +1,125 @@
+// pkg/analysis/insights_test.go
+package analysis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInsightFindings(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		status string // 'status' del DataplaneInsight, vacío si no hay insight
+		want   []string
+	}{
+		{
+			name: "sin insight",
+			want: []string{RuleDataplaneNeverConnected.ID},
+		},
+		{
+			name:   "sin suscripciones",
+			status: `{subscriptions: []}`,
+			want:   []string{RuleDataplaneNeverConnected.ID},
+		},
+		{
+			name:   "conectado",
+			status: `{subscriptions: [{controlPlaneInstanceId: cp-a, connectTime: "2026-01-01T10:00:00Z"}]}`,
+		},
+		{
+			name: "desconectado",
+			status: `{subscriptions: [{controlPlaneInstanceId: cp-a, connectTime: "2026-01-01T10:00:00Z",
+				disconnectTime: "2026-01-01T11:00:00Z"}]}`,
+			want: []string{RuleDataplaneDisconnected.ID},
+		},
+		{
+			name: "suscripción anterior sin cerrar",
+			status: `{subscriptions: [{controlPlaneInstanceId: cp-a, connectTime: "2026-01-01T09:00:00Z"},
+				{controlPlaneInstanceId: cp-b, connectTime: "2026-01-01T10:00:00Z"}]}`,
+			want: []string{RuleDataplaneStaleSubscription.ID},
+		},
+		{
+			name: "suscripciones anteriores cerradas",
+			status: `{subscriptions: [{controlPlaneInstanceId: cp-a, disconnectTime: "2026-01-01T09:30:00Z"},
+				{controlPlaneInstanceId: cp-b, connectTime: "2026-01-01T10:00:00Z"}]}`,
+		},
+		{
+			name: "certificado a punto de caducar",
+			status: `{subscriptions: [{controlPlaneInstanceId: cp-a}],
+				mTLS: {certificateExpirationTime: "2026-01-01T18:00:00Z", certificateRegenerations: 3}}`,
+			want: []string{RuleDataplaneCertExpiring.ID},
+		},
+		{
+			name: "certificado fuera de la ventana",
+			status: `{subscriptions: [{controlPlaneInstanceId: cp-a}],
+				mTLS: {certificateExpirationTime: "2026-01-03T12:00:00Z"}}`,
+		},
+		{
+			name: "certificado caducado",
+			status: `{subscriptions: [{controlPlaneInstanceId: cp-a, disconnectTime: "2026-01-01T11:00:00Z"}],
+				mTLS: {certificateExpirationTime: "2026-01-01T12:00:00Z"}}`,
+			want: []string{RuleDataplaneDisconnected.ID, RuleDataplaneCertExpired.ID},
+		},
+		{
+			name: "versiones incompatibles",
+			status: `{subscriptions: [{controlPlaneInstanceId: cp-a, version: {
+				kumaDp: {version: 2.5.0, kumaCpCompatible: false},
+				envoy: {version: 1.28.0, kumaDpCompatible: true}}}]}`,
+			want: []string{RuleDataplaneVersionIncompatible.ID},
+		},
+		{
+			name:   "versión sin información de compatibilidad",
+			status: `{subscriptions: [{controlPlaneInstanceId: cp-a, version: {kumaDp: {version: 2.5.0}}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var insight *DataplaneInsightStatus
+			if tt.status != "" {
+				source := newFakeSource(t, "apiVersion: kuma.io/v1alpha1\nkind: DataplaneInsight\nmetadata: {name: web-1}\nstatus: "+tt.status)
+				items, _ := source.List(context.Background(), DataplaneInsightType, "")
+				insight = parseDataplaneInsight(items[0])
+			}
+
+			var got []string
+			for _, finding := range insightFindings(ResourceRef{Kind: "Dataplane", Name: "web-1"}, insight, now, 24*time.Hour) {
+				got = append(got, finding.RuleID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hallazgos = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDataplaneInsight(t *testing.T) {
+	source := newFakeSource(t, `
+apiVersion: kuma.io/v1alpha1
+kind: DataplaneInsight
+metadata: {name: web-1}
+status:
+  subscriptions:
+  - {controlPlaneInstanceId: cp-a, connectTime: "2026-01-01T09:00:00Z"}
+  - controlPlaneInstanceId: cp-b
+    connectTime: "2026-01-01T10:00:00Z"
+    status: {lastUpdateTime: "2026-01-01T10:05:00Z"}
+    version: {kumaDp: {version: 2.9.3}, envoy: {version: 1.31.2}}
+`)
+	items, _ := source.List(context.Background(), DataplaneInsightType, "")
+	got := parseDataplaneInsight(items[0])
+
+	if !got.Connected || got.ControlPlaneInstance != "cp-b" {
+		t.Errorf("conexión = %v con %q, se esperaba conectado a cp-b (la última suscripción)", got.Connected, got.ControlPlaneInstance)
+	}
+	if got.KumaDpVersion != "2.9.3" || got.EnvoyVersion != "1.31.2" {
+		t.Errorf("versiones = kuma-dp %q, Envoy %q, se esperaba 2.9.3 y 1.31.2", got.KumaDpVersion, got.EnvoyVersion)
+	}
+	if want := time.Date(2026, 1, 1, 10, 5, 0, 0, time.UTC); got.LastUpdateTime == nil || !got.LastUpdateTime.Equal(want) {
+		t.Errorf("lastUpdateTime = %v, se esperaba %v", got.LastUpdateTime, want)
+	}
+	if want := []string{"cp-a"}; !reflect.DeepEqual(got.StaleSubscriptions, want) {
+		t.Errorf("suscripciones sin cerrar = %v, se esperaba %v", got.StaleSubscriptions, want)
+	}
+}
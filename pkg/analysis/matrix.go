@@ -0,0 +1,103 @@
+// pkg/analysis/matrix.go
+package analysis
+
+import (
+	"context"
+	"time"
+)
+
+// PermissionMatrix es la decisión de MeshTrafficPermission para cada par origen × destino
+// de servicios de un mesh.
+type PermissionMatrix struct {
+	Mesh         string    `json:"mesh"`
+	GeneratedAt  time.Time `json:"generatedAt"`
+	MTLS         MTLSState `json:"mtls"`
+	Sources      []string  `json:"sources"`
+	Destinations []string  `json:"destinations"`
+	// Cells tiene una fila por origen y una columna por destino, en el orden de Sources y
+	// Destinations.
+	Cells [][]MatrixCell `json:"cells"`
+}
+
+// MatrixCell es la decisión para un par de servicios.
+type MatrixCell struct {
+	From           string       `json:"from"`
+	To             string       `json:"to"`
+	Verdict        Verdict      `json:"verdict"`
+	Action         string       `json:"action,omitempty"`
+	DecidingPolicy *ResourceRef `json:"decidingPolicy,omitempty"`
+	// Wildcard indica que el tráfico está permitido por una regla 'from' de tipo 'kind: Mesh',
+	// es decir, que cualquier servicio del mesh tendría el mismo acceso.
+	Wildcard bool `json:"wildcard"`
+}
+
+// MatrixCounts resume las celdas de una matriz.
+type MatrixCounts struct {
+	Allowed  int `json:"allowed"`
+	Denied   int `json:"denied"`
+	Wildcard int `json:"wildcard"`
+}
+
+// Counts cuenta los pares permitidos, denegados y permitidos por un comodín.
+func (m *PermissionMatrix) Counts() MatrixCounts {
+	var counts MatrixCounts
+	for _, row := range m.Cells {
+		for _, cell := range row {
+			if cell.Verdict == VerdictAllowed {
+				counts.Allowed++
+			} else {
+				counts.Denied++
+			}
+			if cell.Wildcard {
+				counts.Wildcard++
+			}
+		}
+	}
+	return counts
+}
+
+// BuildPermissionMatrices calcula la matriz de permisos de cada mesh seleccionado con
+// env.Meshes. Los orígenes incluyen los gateways; los destinos son los servicios con sidecar,
+// que son a los que se aplican las MeshTrafficPermission.
+func BuildPermissionMatrices(ctx context.Context, env *Env) ([]*PermissionMatrix, error) {
+	meshes, err := ResolveMeshes(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	var matrices []*PermissionMatrix
+	for _, mesh := range meshes {
+		engine, err := NewPermissionEngine(ctx, env.forMesh(mesh))
+		if err != nil {
+			return nil, err
+		}
+		matrices = append(matrices, buildPermissionMatrix(engine))
+	}
+	return matrices, nil
+}
+
+func buildPermissionMatrix(engine *PermissionEngine) *PermissionMatrix {
+	matrix := &PermissionMatrix{
+		Mesh:         engine.mesh,
+		GeneratedAt:  time.Now(),
+		MTLS:         engine.mtls,
+		Sources:      engine.Sources(),
+		Destinations: engine.Destinations(),
+		Cells:        [][]MatrixCell{},
+	}
+	for _, from := range matrix.Sources {
+		row := make([]MatrixCell, 0, len(matrix.Destinations))
+		for _, to := range matrix.Destinations {
+			reachability := engine.Evaluate(from, to)
+			row = append(row, MatrixCell{
+				From:           from,
+				To:             to,
+				Verdict:        reachability.Verdict,
+				Action:         reachability.Action,
+				DecidingPolicy: reachability.DecidingPolicy,
+				Wildcard:       reachability.Verdict == VerdictAllowed && reachability.FromWildcard(),
+			})
+		}
+		matrix.Cells = append(matrix.Cells, row)
+	}
+	return matrix
+}
@@ -0,0 +1,89 @@
+// pkg/analysis/mesh.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// AllMeshes es el valor de --mesh que selecciona todos los meshes del clúster.
+	AllMeshes = "all"
+	// defaultMesh es el mesh al que Kuma asigna los recursos que no indican ninguno.
+	defaultMesh = "default"
+	// meshLabel es la etiqueta con la que Kuma asocia un recurso a su mesh en Kubernetes.
+	meshLabel = "kuma.io/mesh"
+)
+
+// ResolveMeshes devuelve, ordenados, los meshes que se deben analizar según env.Meshes.
+// Si no se indicó ninguno, o se indicó "all", se analizan todos los Mesh del clúster.
+func ResolveMeshes(ctx context.Context, env *Env) ([]string, error) {
+	requested := make(map[string]bool)
+	for _, mesh := range env.Meshes {
+		requested[mesh] = true
+	}
+	if len(requested) > 0 && !requested[AllMeshes] {
+		return sortedKeys(requested), nil
+	}
+
+	meshes, err := env.Source.List(ctx, MeshType, "")
+	if IsNotInstalled(err) {
+		// Sin el CRD de Mesh analizamos el mesh por defecto, para que cada analizador informe
+		// de los CRDs que le faltan en lugar de abortar todo el reporte.
+		return []string{defaultMesh}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al listar Meshes: %w", err)
+	}
+	names := make(map[string]bool)
+	for _, mesh := range meshes {
+		names[mesh.GetName()] = true
+	}
+	if len(names) == 0 {
+		// Sin ningún Mesh no hay nada que agrupar; analizamos el mesh por defecto para
+		// que los analizadores informen de su ausencia.
+		return []string{defaultMesh}, nil
+	}
+	return sortedKeys(names), nil
+}
+
+// meshOf devuelve el mesh al que pertenece un recurso de Kuma. En Kubernetes las políticas
+// nuevas usan la etiqueta kuma.io/mesh y los recursos clásicos (Dataplane, TrafficPermission...)
+// el campo 'mesh' de primer nivel; si no hay ninguno, Kuma usa el mesh 'default'.
+func meshOf(obj unstructured.Unstructured) string {
+	if mesh := obj.GetLabels()[meshLabel]; mesh != "" {
+		return mesh
+	}
+	if mesh, found, _ := unstructured.NestedString(obj.Object, "mesh"); found && mesh != "" {
+		return mesh
+	}
+	return defaultMesh
+}
+
+// filterByMesh devuelve los recursos que pertenecen al mesh indicado.
+// Un mesh vacío significa "sin filtrar", para cuando un analizador se invoca sin runner.
+func filterByMesh(items []unstructured.Unstructured, mesh string) []unstructured.Unstructured {
+	if mesh == "" {
+		return items
+	}
+	var filtered []unstructured.Unstructured
+	for _, item := range items {
+		if meshOf(item) == mesh {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// sortedKeys devuelve las claves de un mapa ordenadas.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -5,85 +5,79 @@ import (
 	"context"
 	"fmt"
 	"time"
-
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
 )
 
+func init() {
+	Register(NewAnalyzer("observability", "Políticas de Observabilidad (Logs, Metrics, Traces)", CategoryObservability, AnalyzeObservability, "obs"))
+}
+
 // AnalyzeObservability revisa la configuración de políticas como MeshLog, MeshMetric, etc.
-func AnalyzeObservability(client dynamic.Interface) (*ValidationResult, error) {
-	var findings []interface{}
+func AnalyzeObservability(ctx context.Context, env *Env) (*ValidationResult, error) {
+	var findings []Finding
 
 	// 1. Analizar MeshLog
-	logGVR := schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: "meshlogs"}
-	logPolicies, err := client.Resource(logGVR).List(context.TODO(), v1.ListOptions{})
-	if err != nil {
+	logPolicies, err := env.Source.List(ctx, MeshLogType, "")
+	logItems := filterByMesh(logPolicies, env.Mesh)
+	switch {
+	case IsNotInstalled(err):
+		findings = append(findings, notInstalledFinding(MeshLogType))
+	case err != nil:
 		return nil, fmt.Errorf("error al listar MeshLogs: %w", err)
-	}
-	if len(logPolicies.Items) == 0 {
-		findings = append(findings, ObservabilityFinding{
-			Level:      "WARN",
-			PolicyType: "MeshLog",
-			Resource:   "Global",
-			Message:    "No se encontró ninguna política MeshLog. Los logs de acceso no están siendo capturados.",
-		})
-	} else {
-		for _, policy := range logPolicies.Items {
-			findings = append(findings, ObservabilityFinding{
-				Level:      "INFO",
-				PolicyType: "MeshLog",
-				Resource:   policy.GetName(),
-				Message:    "Política de logging encontrada.",
-			})
+	case len(logItems) == 0:
+		findings = append(findings, RuleMeshLogMissing.Finding(
+			ResourceRef{Kind: "MeshLog", Mesh: env.Mesh, Name: "Global"},
+			"No se encontró ninguna política MeshLog. Los logs de acceso no están siendo capturados.",
+		))
+	default:
+		for _, policy := range logItems {
+			findings = append(findings, RuleMeshLogFound.Finding(
+				policyRef(policy),
+				"Política de logging encontrada.",
+			))
 		}
 	}
 
 	// 2. Analizar MeshMetric
-	metricGVR := schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: "meshmetrics"}
-	metricPolicies, err := client.Resource(metricGVR).List(context.TODO(), v1.ListOptions{})
-	if err != nil {
+	metricPolicies, err := env.Source.List(ctx, MeshMetricType, "")
+	metricItems := filterByMesh(metricPolicies, env.Mesh)
+	switch {
+	case IsNotInstalled(err):
+		findings = append(findings, notInstalledFinding(MeshMetricType))
+	case err != nil:
 		return nil, fmt.Errorf("error al listar MeshMetrics: %w", err)
-	}
-	if len(metricPolicies.Items) == 0 {
-		findings = append(findings, ObservabilityFinding{
-			Level:      "WARN",
-			PolicyType: "MeshMetric",
-			Resource:   "Global",
-			Message:    "No se encontró ninguna política MeshMetric. Las métricas para Prometheus pueden no estar habilitadas.",
-		})
-	} else {
-		for _, policy := range metricPolicies.Items {
-			findings = append(findings, ObservabilityFinding{
-				Level:      "INFO",
-				PolicyType: "MeshMetric",
-				Resource:   policy.GetName(),
-				Message:    "Política de métricas encontrada.",
-			})
+	case len(metricItems) == 0:
+		findings = append(findings, RuleMeshMetricMissing.Finding(
+			ResourceRef{Kind: "MeshMetric", Mesh: env.Mesh, Name: "Global"},
+			"No se encontró ninguna política MeshMetric. Las métricas para Prometheus pueden no estar habilitadas.",
+		))
+	default:
+		for _, policy := range metricItems {
+			findings = append(findings, RuleMeshMetricFound.Finding(
+				policyRef(policy),
+				"Política de métricas encontrada.",
+			))
 		}
 	}
 
 	// 3. Analizar MeshTrace
-	traceGVR := schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: "meshtraces"}
-	tracePolicies, err := client.Resource(traceGVR).List(context.TODO(), v1.ListOptions{})
-	if err != nil {
+	tracePolicies, err := env.Source.List(ctx, MeshTraceType, "")
+	traceItems := filterByMesh(tracePolicies, env.Mesh)
+	switch {
+	case IsNotInstalled(err):
+		findings = append(findings, notInstalledFinding(MeshTraceType))
+	case err != nil:
 		return nil, fmt.Errorf("error al listar MeshTraces: %w", err)
-	}
-	if len(tracePolicies.Items) == 0 {
-		findings = append(findings, ObservabilityFinding{
-			Level:      "WARN",
-			PolicyType: "MeshTrace",
-			Resource:   "Global",
-			Message:    "No se encontró ninguna política MeshTrace. El tracing distribuido puede no estar configurado.",
-		})
-	} else {
-		for _, policy := range tracePolicies.Items {
-			findings = append(findings, ObservabilityFinding{
-				Level:      "INFO",
-				PolicyType: "MeshTrace",
-				Resource:   policy.GetName(),
-				Message:    "Política de tracing encontrada.",
-			})
+	case len(traceItems) == 0:
+		findings = append(findings, RuleMeshTraceMissing.Finding(
+			ResourceRef{Kind: "MeshTrace", Mesh: env.Mesh, Name: "Global"},
+			"No se encontró ninguna política MeshTrace. El tracing distribuido puede no estar configurado.",
+		))
+	default:
+		for _, policy := range traceItems {
+			findings = append(findings, RuleMeshTraceFound.Finding(
+				policyRef(policy),
+				"Política de tracing encontrada.",
+			))
 		}
 	}
 
@@ -0,0 +1,141 @@
+// pkg/analysis/orphans.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func init() {
+	Register(NewAnalyzer("orphaned-policies", "Políticas Huérfanas (targetRef sin Dataplanes)", CategoryPolicies, AnalyzeOrphanedPolicies, "orphans"))
+}
+
+const orphanedPoliciesTitle = "Análisis de Políticas Huérfanas"
+
+// AnalyzeOrphanedPolicies busca, en todos los tipos de política de PolicyTypes, los targetRef
+// que no seleccionan nada en el mesh: un 'spec.targetRef' que no selecciona ningún Dataplane
+// (la política no tiene efecto) o una entrada de 'to'/'from' que no coincide con ningún
+// servicio (la entrada no tiene efecto). Suele ser configuración que quedó tras renombrar un
+// servicio o cambiar sus etiquetas.
+func AnalyzeOrphanedPolicies(ctx context.Context, env *Env) (*ValidationResult, error) {
+	// --namespace acota los workloads analizados, pero para saber si un selector no encaja
+	// con nada hay que ver todos los Dataplanes del mesh.
+	scoped := *env
+	scoped.Namespace = ""
+	resolver, err := NewTargetResolver(ctx, &scoped)
+	if err != nil {
+		if result, ok := skipIfNotInstalled(orphanedPoliciesTitle, err); ok {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	result := &ValidationResult{Title: orphanedPoliciesTitle, GeneratedAt: time.Now()}
+	if len(resolver.Proxies()) == 0 {
+		// Sin Dataplanes todas las políticas parecerían huérfanas.
+		result.Findings = append(result.Findings, RuleOrphansNotEvaluated.Finding(
+			ResourceRef{Kind: "Mesh", Mesh: env.Mesh, Name: env.Mesh},
+			"No hay Dataplanes en el mesh; no se pueden detectar políticas huérfanas.",
+		))
+		return result, nil
+	}
+
+	externals, diagnostics := externalServices(ctx, env)
+	result.Diagnostics = append(result.Diagnostics, diagnostics...)
+
+	for _, policyType := range PolicyTypes {
+		policies, err := env.Source.List(ctx, policyType, "")
+		switch {
+		case IsNotInstalled(err):
+			continue // Lo informa el análisis de CRDs
+		case err != nil:
+			result.Diagnostics = append(result.Diagnostics,
+				fmt.Sprintf("no se pudieron leer las políticas %s: %v", policyType.Kind, err))
+			continue
+		}
+		for _, policy := range filterByMesh(policies, env.Mesh) {
+			result.Findings = append(result.Findings, orphanedSelectors(resolver, externals, policy)...)
+		}
+	}
+
+	if len(result.Findings) == 0 {
+		result.Findings = append(result.Findings, RuleNoOrphanedPolicies.Finding(
+			ResourceRef{Kind: "Mesh", Mesh: env.Mesh, Name: env.Mesh},
+			"Todos los targetRef de las políticas seleccionan al menos un Dataplane o servicio del mesh.",
+		))
+	}
+	return result, nil
+}
+
+// orphanedSelectors devuelve los hallazgos de los targetRef de una política que no
+// seleccionan nada. Si el 'spec.targetRef' ya es huérfano, sus entradas no se revisan: la
+// política entera no tiene efecto. Las entradas 'to' de tipo MeshService que nombran un
+// servicio externo (externals) apuntan a un destino fuera del mesh y no son huérfanas.
+func orphanedSelectors(resolver *TargetResolver, externals map[string]bool, policy unstructured.Unstructured) []Finding {
+	ref := policyRef(policy)
+	top := policyTargetRef(policy)
+	if resolvableTargetRef(top) && len(resolver.SelectProxies(top)) == 0 {
+		return []Finding{RulePolicyOrphaned.Finding(ref, fmt.Sprintf(
+			"spec.targetRef (%s) no selecciona ningún Dataplane del mesh: la política no tiene efecto.", top,
+		))}
+	}
+
+	var findings []Finding
+	for _, section := range []string{SectionTo, SectionFrom} {
+		for i, rule := range policyRules(policy, section) {
+			if !resolvableTargetRef(rule.TargetRef) || len(resolver.SelectServices(rule.TargetRef)) > 0 {
+				continue
+			}
+			if section == SectionTo && rule.TargetRef.Kind == TargetMeshService && externals[rule.TargetRef.Name] {
+				continue
+			}
+			findings = append(findings, RulePolicyEntryOrphaned.Finding(ref, fmt.Sprintf(
+				"spec.%s[%d].targetRef (%s) no coincide con ningún servicio del mesh: la entrada no tiene efecto.", section, i, rule.TargetRef,
+			)))
+		}
+	}
+	return findings
+}
+
+// resolvableTargetRef indica si el resolver sabe evaluar un targetRef. 'kind: Mesh' siempre
+// selecciona algo y los kinds que no modela (MeshExternalService, MeshHTTPRoute...) no se
+// pueden contrastar con los Dataplanes, así que no se consideran huérfanos.
+func resolvableTargetRef(ref TargetRef) bool {
+	switch ref.Kind {
+	case TargetMeshSubset, TargetMeshService, TargetMeshServiceSubset, TargetMeshGateway:
+		return true
+	default:
+		return false
+	}
+}
+
+// externalServices devuelve los nombres con los que una entrada 'to' de tipo MeshService puede
+// apuntar a un servicio externo del mesh: el kuma.io/service de cada ExternalService y el
+// nombre de cada MeshExternalService. Los tipos que no están instalados se ignoran; los que no
+// se pueden leer se devuelven como diagnósticos.
+func externalServices(ctx context.Context, env *Env) (map[string]bool, []string) {
+	externals := make(map[string]bool)
+	var diagnostics []string
+	for _, rt := range []ResourceType{ExternalServiceType, MeshExternalServiceType} {
+		items, err := env.Source.List(ctx, rt, "")
+		if err != nil {
+			if !isMissing(err) {
+				diagnostics = append(diagnostics, unavailableDiagnostic(rt, err))
+			}
+			continue
+		}
+		for _, item := range filterByMesh(items, env.Mesh) {
+			if rt.Kind == ExternalServiceType.Kind {
+				if service, _, _ := unstructured.NestedString(item.Object, "spec", "tags", serviceTag); service != "" {
+					externals[service] = true
+				}
+				continue
+			}
+			externals[item.GetName()] = true
+		}
+	}
+	return externals, diagnostics
+}
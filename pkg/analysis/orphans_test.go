@@ -0,0 +1,107 @@
+// pkg/analysis/orphans_test.go
+package analysis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// orphanResources son un servicio web, un ExternalService 'httpbin' y un MeshExternalService
+// 'payments'.
+const orphanResources = `
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: web-1, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 80, tags: {kuma.io/service: web}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: ExternalService
+mesh: default
+metadata: {name: httpbin}
+spec:
+  networking: {address: httpbin.org:443}
+  tags: {kuma.io/service: httpbin, kuma.io/protocol: http}
+---
+apiVersion: kuma.io/v1alpha1
+kind: MeshExternalService
+metadata: {name: payments, namespace: kuma-system, labels: {kuma.io/mesh: default}}
+spec:
+  match: {type: HostnameGenerator, port: 443, protocol: http}
+  endpoints: [{address: payments.example.com, port: 443}]
+`
+
+func TestOrphanedSelectors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   []string
+	}{
+		{
+			name: "spec.targetRef sin Dataplanes",
+			policy: `
+  targetRef: {kind: MeshService, name: missing}
+  to:
+  - targetRef: {kind: MeshService, name: also-missing}`,
+			want: []string{RulePolicyOrphaned.ID},
+		},
+		{
+			name: "entrada to sin servicio",
+			policy: `
+  targetRef: {kind: Mesh}
+  to:
+  - targetRef: {kind: MeshService, name: missing}
+  - targetRef: {kind: MeshService, name: web}`,
+			want: []string{RulePolicyEntryOrphaned.ID},
+		},
+		{
+			name: "entrada to a un ExternalService",
+			policy: `
+  targetRef: {kind: Mesh}
+  to:
+  - targetRef: {kind: MeshService, name: httpbin}`,
+		},
+		{
+			name: "entrada to a un MeshExternalService",
+			policy: `
+  targetRef: {kind: Mesh}
+  to:
+  - targetRef: {kind: MeshService, name: payments}`,
+		},
+		{
+			name: "entrada from con el nombre de un servicio externo",
+			policy: `
+  targetRef: {kind: Mesh}
+  from:
+  - targetRef: {kind: MeshService, name: httpbin}`,
+			want: []string{RulePolicyEntryOrphaned.ID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifests := orphanResources + `---
+apiVersion: kuma.io/v1alpha1
+kind: MeshTimeout
+metadata: {name: timeout, labels: {kuma.io/mesh: default}}
+spec:` + tt.policy
+			env := &Env{Source: newFakeSource(t, manifests), Mesh: "default"}
+			resolver, err := NewTargetResolver(context.Background(), env)
+			if err != nil {
+				t.Fatalf("NewTargetResolver: %v", err)
+			}
+			externals, diagnostics := externalServices(context.Background(), env)
+			if len(diagnostics) > 0 {
+				t.Errorf("diagnósticos inesperados: %v", diagnostics)
+			}
+			policies, _ := env.Source.List(context.Background(), MeshTimeoutType, "")
+
+			var ids []string
+			for _, finding := range orphanedSelectors(resolver, externals, policies[0]) {
+				ids = append(ids, finding.RuleID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("hallazgos = %v, se esperaba %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// pkg/analysis/permissionconflicts.go
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func init() {
+	Register(NewAnalyzer("mtp-conflicts", "MeshTrafficPermissions Anuladas, Duplicadas o en Conflicto", CategoryPolicies, AnalyzePermissionConflicts))
+}
+
+const permissionConflictsTitle = "Análisis de Conflictos entre MeshTrafficPermissions"
+
+// permissionPolicy es una MeshTrafficPermission con sus selectores ya resueltos.
+type permissionPolicy struct {
+	matchedPolicy
+	rules []PolicyRule
+	// inbounds son los inbounds que selecciona 'spec.targetRef' (ver selectedInbounds).
+	inbounds map[string]bool
+}
+
+// AnalyzePermissionConflicts compara las MeshTrafficPermission de un mesh dos a dos, en orden
+// de fusión, y detecta:
+//   - políticas duplicadas: mismo 'spec.targetRef' y mismas reglas 'from';
+//   - conflictos: dos políticas con targetRef del mismo kind sobre los mismos destinos que dan
+//     acciones opuestas (Allow/Deny) al mismo origen, de modo que decide el orden alfabético;
+//   - políticas anuladas: todas sus reglas 'from' quedan sobrescritas por una política que se
+//     aplica después sobre los mismos destinos.
+func AnalyzePermissionConflicts(ctx context.Context, env *Env) (*ValidationResult, error) {
+	policies, err := env.Source.List(ctx, MeshTrafficPermissionType, "")
+	if err != nil {
+		if result, ok := skipIfNotInstalled(permissionConflictsTitle, err); ok {
+			return result, nil
+		}
+		return nil, fmt.Errorf("error al listar MeshTrafficPermissions: %w", err)
+	}
+	resolver, err := NewTargetResolver(ctx, env)
+	if err != nil {
+		if result, ok := skipIfNotInstalled(permissionConflictsTitle, err); ok {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	var ordered []matchedPolicy
+	for _, policy := range filterByMesh(policies, env.Mesh) {
+		ordered = append(ordered, matchedPolicy{policy: policy, targetRef: policyTargetRef(policy)})
+	}
+	sortByMergeOrder(ordered)
+
+	var permissions []permissionPolicy
+	for _, m := range ordered {
+		rules := orderedRules(m.policy, SectionFrom)
+		if len(rules) == 0 {
+			continue // Sin reglas 'from' la política no decide nada
+		}
+		permissions = append(permissions, permissionPolicy{
+			matchedPolicy: m,
+			rules:         rules,
+			inbounds:      resolver.selectedInbounds(m.targetRef),
+		})
+	}
+
+	var findings []Finding
+	for i, earlier := range permissions {
+		shadowed := false
+		for _, later := range permissions[i+1:] {
+			finding, ok := comparePermissions(earlier, later)
+			if !ok {
+				continue
+			}
+			// Basta con nombrar la primera política que anula a otra; las siguientes solo
+			// repetirían el mismo hallazgo.
+			if finding.RuleID == RuleTrafficPermissionShadowed.ID {
+				if shadowed {
+					continue
+				}
+				shadowed = true
+			}
+			findings = append(findings, finding)
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, RuleTrafficPermissionsConsistent.Finding(
+			ResourceRef{Kind: "MeshTrafficPermission", Mesh: env.Mesh, Name: "Global"},
+			"No hay MeshTrafficPermissions anuladas, duplicadas ni en conflicto.",
+		))
+	}
+
+	return &ValidationResult{
+		Title:       permissionConflictsTitle,
+		GeneratedAt: time.Now(),
+		Findings:    findings,
+	}, nil
+}
+
+// comparePermissions compara dos políticas, earlier antes que later en el orden de fusión, y
+// devuelve como mucho un hallazgo sobre earlier (la que pierde), que nombra a ambas.
+func comparePermissions(earlier, later permissionPolicy) (Finding, bool) {
+	sameSelector := earlier.targetRef.key() == later.targetRef.key()
+	laterName := policyRef(later.policy).String()
+
+	if sameSelector && rulesSignature(earlier.rules) == rulesSignature(later.rules) {
+		return RuleTrafficPermissionDuplicate.Finding(
+			policyRef(earlier.policy),
+			fmt.Sprintf("Duplica a %s: mismo targetRef (%s) y mismas reglas 'from'.", laterName, earlier.targetRef),
+		), true
+	}
+
+	overlapping := sameSelector || intersects(earlier.inbounds, later.inbounds)
+	if overlapping && earlier.targetRef.Kind == later.targetRef.Kind {
+		if source, earlierAction, laterAction, ok := conflictingRule(earlier.rules, later.rules); ok {
+			return RuleTrafficPermissionConflict.Finding(
+				policyRef(earlier.policy),
+				fmt.Sprintf(
+					"Conflicto con %s: ambas tienen un targetRef %s sobre los mismos destinos y dan acciones opuestas al origen %s (%s aquí, %s en la otra). Gana %s solo porque su nombre va antes en orden alfabético.",
+					laterName, earlier.targetRef.Kind, source, earlierAction, laterAction, laterName,
+				),
+			), true
+		}
+	}
+
+	covered := sameSelector || (len(earlier.inbounds) > 0 && isSubset(earlier.inbounds, later.inbounds))
+	if covered && rulesCovered(earlier.rules, later.rules) {
+		return RuleTrafficPermissionShadowed.Finding(
+			policyRef(earlier.policy),
+			fmt.Sprintf(
+				"Todas sus reglas 'from' quedan anuladas por %s (targetRef %s), que se aplica después sobre los mismos destinos; esta política no tiene ningún efecto.",
+				laterName, later.targetRef,
+			),
+		), true
+	}
+	return Finding{}, false
+}
+
+// conflictingRule busca un origen (el mismo targetRef 'from') al que las dos listas de reglas
+// dan acciones opuestas: una lo permite (Allow o AllowWithShadowDeny) y la otra lo deniega.
+func conflictingRule(earlier, later []PolicyRule) (string, string, string, bool) {
+	for _, e := range earlier {
+		earlierAction, _, _ := unstructured.NestedString(e.Default, "action")
+		for _, l := range later {
+			if e.TargetRef.key() != l.TargetRef.key() {
+				continue
+			}
+			laterAction, _, _ := unstructured.NestedString(l.Default, "action")
+			if (earlierAction == ActionDeny) != (laterAction == ActionDeny) && earlierAction != "" && laterAction != "" {
+				return e.TargetRef.String(), earlierAction, laterAction, true
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// rulesCovered indica si cada regla de earlier queda sobrescrita por alguna regla de later:
+// una regla 'from' con 'kind: Mesh' (sin proxyTypes) o con el mismo targetRef.
+func rulesCovered(earlier, later []PolicyRule) bool {
+	for _, e := range earlier {
+		covered := false
+		for _, l := range later {
+			if (l.TargetRef.Kind == TargetMesh && len(l.TargetRef.ProxyTypes) == 0) || l.TargetRef.key() == e.TargetRef.key() {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// rulesSignature representa un conjunto de reglas de forma canónica para compararlas.
+func rulesSignature(rules []PolicyRule) string {
+	signatures := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		conf, _ := json.Marshal(rule.Default) // json ordena las claves de los mapas
+		signatures = append(signatures, rule.TargetRef.key()+"="+string(conf))
+	}
+	sort.Strings(signatures)
+	return strings.Join(signatures, ";")
+}
+
+func intersects(a, b map[string]bool) bool {
+	for key := range a {
+		if b[key] {
+			return true
+		}
+	}
+	return false
+}
+
+func isSubset(a, b map[string]bool) bool {
+	for key := range a {
+		if !b[key] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+// pkg/analysis/permissionconflicts_test.go
+package analysis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// conflictDataplanes son los servicios web, backend (del equipo back) y db.
+const conflictDataplanes = `
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: web-1, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 80, tags: {kuma.io/service: web}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: backend-1, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 3001, tags: {kuma.io/service: backend, team: back}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: db-1, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 5432, tags: {kuma.io/service: db}}]}}
+`
+
+// permission devuelve una MeshTrafficPermission con el targetRef y las reglas 'from' indicados.
+func permission(name, targetRef, from string) string {
+	return "---\napiVersion: kuma.io/v1alpha1\nkind: MeshTrafficPermission\nmetadata: {name: " + name +
+		", labels: {kuma.io/mesh: default}}\nspec:\n  targetRef: " + targetRef + "\n  from:\n" + from
+}
+
+const (
+	fromWebAllow  = "  - targetRef: {kind: MeshService, name: web}\n    default: {action: Allow}\n"
+	fromWebDeny   = "  - targetRef: {kind: MeshService, name: web}\n    default: {action: Deny}\n"
+	fromMeshDeny  = "  - targetRef: {kind: Mesh}\n    default: {action: Deny}\n"
+	toBackend     = "{kind: MeshService, name: backend}"
+	toDB          = "{kind: MeshService, name: db}"
+	toBackendTeam = "{kind: MeshSubset, tags: {team: back}}"
+)
+
+func TestAnalyzePermissionConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies string
+		want     []string // "<regla> <política>"
+	}{
+		{
+			name:     "sin solapamientos",
+			policies: permission("backend", toBackend, fromWebAllow) + permission("db", toDB, fromWebDeny),
+			want:     []string{RuleTrafficPermissionsConsistent.ID + " Global"},
+		},
+		{
+			// A igualdad de kind se aplica antes la de nombre mayor: b pierde.
+			name:     "duplicadas",
+			policies: permission("a", toBackend, fromWebAllow) + permission("b", toBackend, fromWebAllow),
+			want:     []string{RuleTrafficPermissionDuplicate.ID + " b"},
+		},
+		{
+			name:     "acciones opuestas al mismo origen",
+			policies: permission("a", toBackend, fromWebDeny) + permission("b", toBackend, fromWebAllow),
+			want:     []string{RuleTrafficPermissionConflict.ID + " b"},
+		},
+		{
+			name:     "anulada por una regla Mesh sobre el mismo targetRef",
+			policies: permission("a", toBackend, fromMeshDeny) + permission("z", toBackend, fromWebAllow),
+			want:     []string{RuleTrafficPermissionShadowed.ID + " z"},
+		},
+		{
+			// La MeshSubset es menos específica y se aplica antes, pero solo selecciona
+			// inbounds de backend, que la MeshService sobrescribe por completo.
+			name:     "anulada por una política más específica sobre los mismos inbounds",
+			policies: permission("team", toBackendTeam, fromWebAllow) + permission("backend", toBackend, fromMeshDeny),
+			want:     []string{RuleTrafficPermissionShadowed.ID + " team"},
+		},
+		{
+			name:     "una regla de otro origen no anula",
+			policies: permission("a", toBackend, "  - targetRef: {kind: MeshService, name: db}\n    default: {action: Deny}\n") + permission("z", toBackend, fromWebAllow),
+			want:     []string{RuleTrafficPermissionsConsistent.ID + " Global"},
+		},
+		{
+			name:     "kinds distintos no entran en conflicto",
+			policies: permission("team", toBackendTeam, fromWebDeny) + permission("backend", toBackend, fromWebAllow),
+			want:     []string{RuleTrafficPermissionShadowed.ID + " team"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &Env{Source: newFakeSource(t, conflictDataplanes+tt.policies), Mesh: "default"}
+			result, err := AnalyzePermissionConflicts(context.Background(), env)
+			if err != nil {
+				t.Fatalf("AnalyzePermissionConflicts: %v", err)
+			}
+			var got []string
+			for _, finding := range result.Findings {
+				got = append(got, finding.RuleID+" "+finding.Resource.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hallazgos = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// pkg/analysis/protocols.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func init() {
+	Register(NewAnalyzer("protocols", "Protocolo de los Servicios (appProtocol y Políticas HTTP)", CategoryPolicies, AnalyzeProtocols, "protocol"))
+}
+
+const protocolsTitle = "Análisis del Protocolo de los Servicios"
+
+// kumaProtocols son los valores de kuma.io/protocol que reconoce Kuma; cualquier otro se
+// trata como tcp.
+var kumaProtocols = map[string]bool{"tcp": true, "http": true, "http2": true, "grpc": true, "kafka": true}
+
+// httpProtocols son los protocolos a los que Kuma aplica la configuración HTTP (retries HTTP,
+// rutas, inyección de fallos...).
+var httpProtocols = map[string]bool{"http": true, "http2": true, "grpc": true}
+
+// AnalyzeProtocols revisa el kuma.io/protocol de los servicios, del que dependen todas las
+// funciones HTTP de Kuma y que un valor erróneo desactiva sin dar ningún error:
+//   - en Kubernetes, compara el de cada inbound con el que declara el puerto de su Service
+//     (appProtocol o la anotación <puerto>.service.kuma.io/protocol) y avisa de los valores que
+//     Kuma no reconoce;
+//   - avisa de las políticas con configuración solo HTTP (la sección http de MeshRetry,
+//     MeshHTTPRoute y MeshFaultInjection) que apuntan a servicios que no son HTTP.
+func AnalyzeProtocols(ctx context.Context, env *Env) (*ValidationResult, error) {
+	// El protocolo de un servicio depende de todas sus réplicas, no solo de las de --namespace.
+	scoped := *env
+	scoped.Namespace = ""
+	resolver, err := NewTargetResolver(ctx, &scoped)
+	if err != nil {
+		if result, ok := skipIfNotInstalled(protocolsTitle, err); ok {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	result := &ValidationResult{Title: protocolsTitle, GeneratedAt: time.Now()}
+	findings, diagnostics := appProtocolFindings(ctx, env, resolver.Proxies())
+	result.Findings = append(result.Findings, findings...)
+	result.Diagnostics = append(result.Diagnostics, diagnostics...)
+
+	findings, diagnostics = httpPolicyFindings(ctx, env, resolver)
+	result.Findings = append(result.Findings, findings...)
+	result.Diagnostics = append(result.Diagnostics, diagnostics...)
+
+	if len(result.Findings) == 0 {
+		result.Findings = append(result.Findings, RuleProtocolsConsistent.Finding(
+			ResourceRef{Kind: "Mesh", Mesh: env.Mesh, Name: env.Mesh},
+			"El protocolo de los inbounds coincide con el de sus Services y ninguna política HTTP apunta a servicios tcp.",
+		))
+	}
+	return result, nil
+}
+
+// servicePortProtocol es el protocolo que Kuma deriva del puerto de un Service de Kubernetes.
+type servicePortProtocol struct {
+	protocol string
+	// unsupported es el valor declarado que Kuma no reconoce, si lo hay. Con appProtocol Kuma
+	// etiqueta el inbound como tcp; con la anotación copia el valor y lo trata como tcp.
+	unsupported string
+	source      string
+}
+
+// appProtocolFindings compara el kuma.io/protocol de los inbounds creados a partir de un
+// Service de Kubernetes (los que tienen las etiquetas k8s.kuma.io/*) con el puerto del Service.
+func appProtocolFindings(ctx context.Context, env *Env, proxies []Proxy) ([]Finding, []string) {
+	var findings []Finding
+	var services map[string]unstructured.Unstructured
+	// expected es el protocolo de cada puerto de Service ("namespace/nombre:puerto"); nil si el
+	// Service o el puerto ya no existen.
+	expected := make(map[string]*servicePortProtocol)
+	// mismatched son, por kuma.io/service, las réplicas indexadas por el protocolo que declaran.
+	mismatched := make(map[string]map[string][]string)
+	wanted := make(map[string]*servicePortProtocol)
+
+	for _, proxy := range proxies {
+		if env.Namespace != "" && proxy.Namespace != env.Namespace {
+			continue
+		}
+		for _, inbound := range proxy.Inbounds {
+			name, port := inbound.Tags[k8sServiceNameTag], inboundServicePort(inbound)
+			if name == "" || port == 0 {
+				continue
+			}
+			namespace := inbound.Tags[k8sNamespaceTag]
+			if namespace == "" {
+				namespace = proxy.Namespace
+			}
+
+			if services == nil {
+				items, err := env.Source.List(ctx, ServiceType, "")
+				if err != nil {
+					return nil, []string{unavailableDiagnostic(ServiceType, err)}
+				}
+				services = make(map[string]unstructured.Unstructured, len(items))
+				for _, item := range items {
+					services[item.GetNamespace()+"/"+item.GetName()] = item
+				}
+			}
+			key := fmt.Sprintf("%s/%s:%d", namespace, name, port)
+			want, ok := expected[key]
+			if !ok {
+				if service, found := services[namespace+"/"+name]; found {
+					want = portProtocol(service, port)
+					if want != nil && want.unsupported != "" {
+						findings = append(findings, RuleAppProtocolUnsupported.Finding(
+							ResourceRef{Kind: "Service", Mesh: proxy.Mesh, Namespace: namespace, Name: name},
+							fmt.Sprintf("El puerto %d declara %s '%s', que Kuma no reconoce: el servicio se trata como tcp.", port, want.source, want.unsupported),
+						))
+					}
+				}
+				expected[key] = want
+			}
+			if want == nil {
+				continue
+			}
+
+			declared := inbound.Tags[protocolTag]
+			if declared == "" {
+				declared = "tcp"
+			}
+			if declared != want.protocol {
+				service := inbound.Service()
+				if mismatched[service] == nil {
+					mismatched[service] = make(map[string][]string)
+				}
+				mismatched[service][declared] = append(mismatched[service][declared], ResourceRef{Namespace: proxy.Namespace, Name: proxy.Name}.String())
+				wanted[service] = want
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(mismatched) {
+		want := wanted[name]
+		findings = append(findings, RuleProtocolTagMismatch.Finding(
+			ResourceRef{Kind: "Service", Mesh: env.Mesh, Name: name},
+			fmt.Sprintf("Los inbounds declaran un %s distinto del que se deriva de su Service (%s, por %s): %s.",
+				protocolTag, want.protocol, want.source, describeValues(mismatched[name], "sin etiqueta")),
+		))
+	}
+	return findings, nil
+}
+
+// inboundServicePort devuelve el puerto del Service del que sale un inbound: el de la etiqueta
+// k8s.kuma.io/service-port o, si no está, el sufijo de kuma.io/service (backend_demo_svc_3001).
+func inboundServicePort(inbound Inbound) int64 {
+	value := inbound.Tags[k8sServicePortTag]
+	if value == "" {
+		if i := strings.LastIndex(inbound.Service(), "_svc_"); i >= 0 {
+			value = inbound.Service()[i+len("_svc_"):]
+		}
+	}
+	port, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return port
+}
+
+// portProtocol reproduce cómo deriva Kuma el kuma.io/protocol de un puerto de un Service
+// (ProtocolTagFor): si el puerto tiene appProtocol, manda siempre, en minúsculas si Kuma lo
+// reconoce y como tcp si no; si no, se copia en minúsculas la anotación
+// <puerto>.service.kuma.io/protocol, aunque Kuma no reconozca el valor; sin ninguna de las
+// dos, tcp. Devuelve nil si el Service no tiene ese puerto.
+func portProtocol(service unstructured.Unstructured, port int64) *servicePortProtocol {
+	ports, _, _ := unstructured.NestedSlice(service.Object, "spec", "ports")
+	for _, item := range ports {
+		portMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if number, _, _ := unstructured.NestedInt64(portMap, "port"); number != port {
+			continue
+		}
+		if appProtocol, found, _ := unstructured.NestedString(portMap, "appProtocol"); found && appProtocol != "" {
+			if value := strings.ToLower(appProtocol); kumaProtocols[value] {
+				return &servicePortProtocol{protocol: value, source: "appProtocol"}
+			}
+			return &servicePortProtocol{protocol: "tcp", unsupported: appProtocol, source: "appProtocol"}
+		}
+		annotation := fmt.Sprintf("%d.service.kuma.io/protocol", port)
+		if value := service.GetAnnotations()[annotation]; value != "" {
+			result := &servicePortProtocol{protocol: strings.ToLower(value), source: "la anotación " + annotation}
+			if !kumaProtocols[result.protocol] {
+				result.unsupported = value
+			}
+			return result
+		}
+		return &servicePortProtocol{protocol: "tcp", source: "defecto"}
+	}
+	return nil
+}
+
+// httpPolicyFindings busca las políticas con configuración solo HTTP que apuntan de forma
+// explícita (MeshService o MeshServiceSubset) a servicios cuyas réplicas no declaran ningún
+// protocolo HTTP. Los targetRef a todo el mesh o a subconjuntos por etiquetas no se revisan:
+// es normal que incluyan servicios tcp, a los que Kuma simplemente no aplica esa parte.
+func httpPolicyFindings(ctx context.Context, env *Env, resolver *TargetResolver) ([]Finding, []string) {
+	protocols := make(map[string]map[string]bool)
+	for _, proxy := range resolver.Proxies() {
+		for _, inbound := range proxy.Inbounds {
+			if service := inbound.Service(); service != "" {
+				if protocols[service] == nil {
+					protocols[service] = make(map[string]bool)
+				}
+				protocols[service][inbound.Tags[protocolTag]] = true
+			}
+		}
+	}
+
+	var findings []Finding
+	var diagnostics []string
+	for _, policyType := range []ResourceType{MeshRetryType, MeshHTTPRouteType, MeshFaultInjectionType} {
+		policies, err := env.Source.List(ctx, policyType, "")
+		switch {
+		case IsNotInstalled(err):
+			continue // Lo informa el análisis de CRDs
+		case err != nil:
+			diagnostics = append(diagnostics, fmt.Sprintf("no se pudieron leer las políticas %s: %v", policyType.Kind, err))
+			continue
+		}
+		for _, policy := range filterByMesh(policies, env.Mesh) {
+			for _, target := range httpTargets(policyType, policy) {
+				if target.ref.Kind != TargetMeshService && target.ref.Kind != TargetMeshServiceSubset {
+					continue
+				}
+				for _, service := range sortedKeys(resolver.SelectServices(target.ref)) {
+					if declared, ok := nonHTTPProtocol(protocols[service]); ok {
+						findings = append(findings, RuleHTTPPolicyOnTCPService.Finding(policyRef(policy), fmt.Sprintf(
+							"%s apunta a %s, que se declara como %s: Kuma no le aplica esta configuración HTTP.", target.field, service, declared)))
+					}
+				}
+			}
+		}
+	}
+	return findings, diagnostics
+}
+
+// httpTarget es un targetRef de una política cuya configuración solo tiene efecto en HTTP.
+type httpTarget struct {
+	field string
+	ref   TargetRef
+}
+
+// httpTargets devuelve los targetRef con configuración HTTP de una política: las entradas de
+// 'to' de MeshHTTPRoute, las de MeshRetry con sección http y, en MeshFaultInjection (que solo
+// inyecta fallos HTTP), el 'spec.targetRef' y las entradas de 'to'.
+func httpTargets(policyType ResourceType, policy unstructured.Unstructured) []httpTarget {
+	var targets []httpTarget
+	if policyType.Kind == MeshFaultInjectionType.Kind {
+		targets = append(targets, httpTarget{field: "spec.targetRef", ref: policyTargetRef(policy)})
+	}
+	for i, rule := range policyRules(policy, "to") {
+		field := fmt.Sprintf("spec.to[%d]", i)
+		if policyType.Kind == MeshRetryType.Kind {
+			if _, ok := rule.Default["http"]; !ok {
+				continue
+			}
+			field = fmt.Sprintf("La sección http de spec.to[%d]", i)
+		}
+		targets = append(targets, httpTarget{field: field, ref: rule.TargetRef})
+	}
+	return targets
+}
+
+// nonHTTPProtocol indica si ninguna réplica de un servicio declara un protocolo HTTP y
+// devuelve el protocolo con el que se trata.
+func nonHTTPProtocol(declared map[string]bool) (string, bool) {
+	if len(declared) == 0 {
+		return "", false // No es un servicio de un sidecar del mesh
+	}
+	values := make(map[string]bool)
+	for protocol := range declared {
+		if httpProtocols[protocol] {
+			return "", false
+		}
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		values[protocol] = true
+	}
+	return strings.Join(sortedKeys(values), "/"), true
+}
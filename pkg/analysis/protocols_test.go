@@ -0,0 +1,171 @@
+// pkg/analysis/protocols_test.go
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPortProtocol(t *testing.T) {
+	tests := []struct {
+		name        string
+		appProtocol string
+		annotation  string
+		port        int64
+		want        *servicePortProtocol
+	}{
+		{
+			name: "sin appProtocol ni anotación",
+			port: 80,
+			want: &servicePortProtocol{protocol: "tcp", source: "defecto"},
+		},
+		{
+			name:        "appProtocol soportado",
+			appProtocol: "http",
+			port:        80,
+			want:        &servicePortProtocol{protocol: "http", source: "appProtocol"},
+		},
+		{
+			name:        "appProtocol en mayúsculas",
+			appProtocol: "HTTP",
+			port:        80,
+			want:        &servicePortProtocol{protocol: "http", source: "appProtocol"},
+		},
+		{
+			name:        "appProtocol no soportado se trata como tcp",
+			appProtocol: "thrift",
+			port:        80,
+			want:        &servicePortProtocol{protocol: "tcp", unsupported: "thrift", source: "appProtocol"},
+		},
+		{
+			name:        "appProtocol no soportado manda sobre la anotación",
+			appProtocol: "kubernetes.io/h2c",
+			annotation:  "http2",
+			port:        80,
+			want:        &servicePortProtocol{protocol: "tcp", unsupported: "kubernetes.io/h2c", source: "appProtocol"},
+		},
+		{
+			name:        "appProtocol manda sobre la anotación",
+			appProtocol: "grpc",
+			annotation:  "http",
+			port:        80,
+			want:        &servicePortProtocol{protocol: "grpc", source: "appProtocol"},
+		},
+		{
+			name:       "anotación soportada",
+			annotation: "http2",
+			port:       80,
+			want:       &servicePortProtocol{protocol: "http2", source: "la anotación 80.service.kuma.io/protocol"},
+		},
+		{
+			name:       "anotación en mayúsculas",
+			annotation: "GRPC",
+			port:       80,
+			want:       &servicePortProtocol{protocol: "grpc", source: "la anotación 80.service.kuma.io/protocol"},
+		},
+		{
+			name:       "anotación no soportada se copia en minúsculas",
+			annotation: "Thrift",
+			port:       80,
+			want:       &servicePortProtocol{protocol: "thrift", unsupported: "Thrift", source: "la anotación 80.service.kuma.io/protocol"},
+		},
+		{
+			name: "puerto inexistente",
+			port: 8080,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := map[string]interface{}{"port": int64(80)}
+			if tt.appProtocol != "" {
+				port["appProtocol"] = tt.appProtocol
+			}
+			service := unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]interface{}{"name": "backend", "namespace": "demo"},
+				"spec":       map[string]interface{}{"ports": []interface{}{port}},
+			}}
+			if tt.annotation != "" {
+				service.SetAnnotations(map[string]string{"80.service.kuma.io/protocol": tt.annotation})
+			}
+
+			got := portProtocol(service, tt.port)
+			switch {
+			case got == nil || tt.want == nil:
+				if got != tt.want {
+					t.Errorf("portProtocol = %+v, se esperaba %+v", got, tt.want)
+				}
+			case *got != *tt.want:
+				t.Errorf("portProtocol = %+v, se esperaba %+v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+// protocolResources es un Service 'backend' cuyo puerto declara appProtocol en mayúsculas y
+// dos réplicas: una con el kuma.io/protocol que genera Kuma y otra con un valor distinto.
+const protocolResources = `
+apiVersion: v1
+kind: Service
+metadata: {name: backend, namespace: demo}
+spec:
+  ports:
+  - {port: 3001, appProtocol: HTTP}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: backend-1, namespace: demo, labels: {kuma.io/mesh: default}}
+spec:
+  networking:
+    inbound:
+    - port: 3001
+      tags: {kuma.io/service: backend_demo_svc_3001, k8s.kuma.io/service-name: backend, k8s.kuma.io/namespace: demo, kuma.io/protocol: http}
+`
+
+func TestAppProtocolFindings(t *testing.T) {
+	tests := []struct {
+		name     string
+		replica  string
+		findings []string
+	}{
+		{name: "el protocolo coincide con el que genera Kuma"},
+		{
+			name: "una réplica con otro protocolo",
+			replica: `
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: backend-2, namespace: demo, labels: {kuma.io/mesh: default}}
+spec:
+  networking:
+    inbound:
+    - port: 3001
+      tags: {kuma.io/service: backend_demo_svc_3001, k8s.kuma.io/service-name: backend, k8s.kuma.io/namespace: demo, kuma.io/protocol: tcp}
+`,
+			findings: []string{RuleProtocolTagMismatch.ID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &Env{Source: newFakeSource(t, protocolResources+tt.replica), Mesh: "default"}
+			resolver, err := NewTargetResolver(context.Background(), env)
+			if err != nil {
+				t.Fatalf("NewTargetResolver: %v", err)
+			}
+			findings, diagnostics := appProtocolFindings(context.Background(), env, resolver.Proxies())
+			if len(diagnostics) > 0 {
+				t.Errorf("diagnósticos inesperados: %v", diagnostics)
+			}
+			var ids []string
+			for _, finding := range findings {
+				ids = append(ids, finding.RuleID)
+			}
+			if len(ids) != len(tt.findings) || (len(ids) > 0 && ids[0] != tt.findings[0]) {
+				t.Errorf("hallazgos = %v, se esperaba %v", ids, tt.findings)
+			}
+		})
+	}
+}
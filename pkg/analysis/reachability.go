@@ -0,0 +1,293 @@
+// pkg/analysis/reachability.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Acciones de las reglas 'from' de MeshTrafficPermission.
+const (
+	ActionAllow               = "Allow"
+	ActionDeny                = "Deny"
+	ActionAllowWithShadowDeny = "AllowWithShadowDeny"
+)
+
+// Modos del backend de mTLS de un Mesh.
+const (
+	MTLSModeStrict     = "STRICT"
+	MTLSModePermissive = "PERMISSIVE"
+)
+
+// Verdict es la decisión sobre si un servicio puede alcanzar a otro.
+type Verdict string
+
+const (
+	VerdictAllowed Verdict = "allowed"
+	VerdictDenied  Verdict = "denied"
+)
+
+// MTLSState es la configuración de mTLS de un Mesh relevante para MeshTrafficPermission.
+type MTLSState struct {
+	Enabled bool   `json:"enabled"`
+	Backend string `json:"backend,omitempty"`
+	// Mode es STRICT o PERMISSIVE; en PERMISSIVE los proxies aceptan también tráfico en claro,
+	// al que no se aplican las MeshTrafficPermission.
+	Mode string `json:"mode,omitempty"`
+}
+
+// Reachability es el resultado de evaluar si un servicio puede alcanzar a otro.
+type Reachability struct {
+	Mesh    string    `json:"mesh"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Verdict Verdict   `json:"verdict"`
+	MTLS    MTLSState `json:"mtls"`
+	// Action es la acción de la regla que decide; vacía si ninguna regla se aplica o si el
+	// mTLS está desactivado.
+	Action string `json:"action,omitempty"`
+	// DecidingPolicy y DecidingRule son la política y la regla 'from' que deciden.
+	DecidingPolicy *ResourceRef `json:"decidingPolicy,omitempty"`
+	DecidingRule   *TargetRef   `json:"decidingRule,omitempty"`
+	Reason         string       `json:"reason"`
+	// Steps son las reglas que se aplican al par, en orden de fusión: la última decide.
+	Steps []PermissionStep `json:"steps"`
+}
+
+// PermissionStep es una regla 'from' de una MeshTrafficPermission que se aplica a un par de servicios.
+type PermissionStep struct {
+	Policy    ResourceRef `json:"policy"`
+	TargetRef TargetRef   `json:"targetRef"`
+	From      TargetRef   `json:"from"`
+	Action    string      `json:"action"`
+}
+
+// FromWildcard indica si la decisión procede de una regla 'from' de tipo 'kind: Mesh', que
+// permite o deniega a cualquier servicio del mesh.
+func (r *Reachability) FromWildcard() bool {
+	return r.DecidingRule != nil && r.DecidingRule.Kind == TargetMesh
+}
+
+// PermissionEngine evalúa las MeshTrafficPermission de un mesh entre pares de servicios.
+type PermissionEngine struct {
+	mesh     string
+	resolver *TargetResolver
+	// policies son las MeshTrafficPermission del mesh en orden de fusión.
+	policies []matchedPolicy
+	mtls     MTLSState
+	// mtpMissing indica que el CRD de MeshTrafficPermission no está instalado.
+	mtpMissing bool
+	// selected cachea los servicios que selecciona cada targetRef, para que evaluar la
+	// matriz completa de servicios no recorra los Dataplanes en cada par.
+	selected map[string]map[string]bool
+}
+
+// NewPermissionEngine carga el Mesh, sus Dataplanes y sus MeshTrafficPermission. env.Mesh
+// debe indicar el mesh a evaluar.
+func NewPermissionEngine(ctx context.Context, env *Env) (*PermissionEngine, error) {
+	mesh, err := env.Source.Get(ctx, MeshType, "", env.Mesh)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el Mesh '%s': %w", env.Mesh, err)
+	}
+	resolver, err := NewTargetResolver(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	engine := &PermissionEngine{
+		mesh:     env.Mesh,
+		resolver: resolver,
+		mtls:     meshMTLS(*mesh),
+		selected: make(map[string]map[string]bool),
+	}
+
+	policies, err := env.Source.List(ctx, MeshTrafficPermissionType, "")
+	switch {
+	case IsNotInstalled(err):
+		engine.mtpMissing = true
+	case err != nil:
+		return nil, fmt.Errorf("error al listar MeshTrafficPermissions: %w", err)
+	}
+	for _, policy := range filterByMesh(policies, env.Mesh) {
+		engine.policies = append(engine.policies, matchedPolicy{
+			policy:    policy,
+			targetRef: policyTargetRef(policy),
+		})
+	}
+	sortByMergeOrder(engine.policies)
+	return engine, nil
+}
+
+// meshMTLS lee el estado de mTLS de un Mesh. El modo por defecto de un backend es STRICT.
+func meshMTLS(mesh unstructured.Unstructured) MTLSState {
+	enabledBackend, _, _ := unstructured.NestedString(mesh.Object, "spec", "mtls", "enabledBackend")
+	if enabledBackend == "" {
+		return MTLSState{}
+	}
+	state := MTLSState{Enabled: true, Backend: enabledBackend, Mode: MTLSModeStrict}
+	backends, _, _ := unstructured.NestedSlice(mesh.Object, "spec", "mtls", "backends")
+	for _, backend := range backends {
+		backendMap, ok := backend.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _, _ := unstructured.NestedString(backendMap, "name"); name != enabledBackend {
+			continue
+		}
+		if mode, _, _ := unstructured.NestedString(backendMap, "mode"); mode != "" {
+			state.Mode = strings.ToUpper(mode)
+		}
+	}
+	return state
+}
+
+// Sources devuelve, en orden, los servicios del mesh que pueden originar tráfico, incluidos
+// los gateways.
+func (e *PermissionEngine) Sources() []string {
+	return sortedKeys(e.resolver.AllServices())
+}
+
+// Destinations devuelve, en orden, los servicios del mesh que reciben tráfico a través de un
+// sidecar (a los que se aplican las MeshTrafficPermission).
+func (e *PermissionEngine) Destinations() []string {
+	return sortedKeys(e.resolver.SidecarServices())
+}
+
+// ResolveService traduce el nombre de un servicio tal y como lo escribe el usuario (el valor
+// de kuma.io/service o, en Kubernetes, el nombre del Service) a su kuma.io/service.
+func (e *PermissionEngine) ResolveService(name string) (string, error) {
+	services := sortedKeys(e.resolver.ServicesNamed(name))
+	switch len(services) {
+	case 0:
+		return "", fmt.Errorf("no existe el servicio '%s' en el mesh '%s'", name, e.mesh)
+	case 1:
+		return services[0], nil
+	default:
+		for _, service := range services {
+			if service == name {
+				return service, nil
+			}
+		}
+		return "", fmt.Errorf("el nombre '%s' es ambiguo en el mesh '%s'; usa uno de: %s", name, e.mesh, strings.Join(services, ", "))
+	}
+}
+
+// Evaluate decide si el servicio from puede alcanzar al servicio to. Como en Kuma, se
+// fusionan las reglas 'from' que seleccionan al origen de las políticas cuyo 'spec.targetRef'
+// selecciona al destino, en orden de especificidad, y la última decide. Sin ninguna regla que
+// se aplique el tráfico se deniega; con mTLS desactivado las políticas no se aplican.
+func (e *PermissionEngine) Evaluate(from, to string) *Reachability {
+	result := &Reachability{Mesh: e.mesh, From: from, To: to, MTLS: e.mtls, Steps: []PermissionStep{}}
+	for _, m := range e.policies {
+		if !e.selects(m.targetRef, to) {
+			continue
+		}
+		for _, rule := range orderedRules(m.policy, SectionFrom) {
+			action, _, _ := unstructured.NestedString(rule.Default, "action")
+			if action == "" || !e.selects(rule.TargetRef, from) {
+				continue
+			}
+			result.Steps = append(result.Steps, PermissionStep{
+				Policy:    policyRef(m.policy),
+				TargetRef: m.targetRef,
+				From:      rule.TargetRef,
+				Action:    action,
+			})
+		}
+	}
+
+	// Sin mTLS no hay identidad del origen y las reglas no deciden nada.
+	if n := len(result.Steps); n > 0 && e.mtls.Enabled {
+		deciding := result.Steps[n-1]
+		result.Action = deciding.Action
+		result.DecidingPolicy = &deciding.Policy
+		result.DecidingRule = &deciding.From
+	}
+
+	switch {
+	case !e.mtls.Enabled:
+		result.Verdict = VerdictAllowed
+		result.Reason = "mTLS está desactivado en el mesh: las MeshTrafficPermission no se aplican y todo el tráfico está permitido."
+	case result.Action == "" && e.mtpMissing:
+		result.Verdict = VerdictDenied
+		result.Reason = "El CRD de MeshTrafficPermission no está instalado; con mTLS activo el tráfico se deniega por defecto."
+	case result.Action == "":
+		result.Verdict = VerdictDenied
+		result.Reason = "Ninguna MeshTrafficPermission permite este tráfico; con mTLS activo se deniega por defecto."
+	case result.Action == ActionDeny:
+		result.Verdict = VerdictDenied
+		result.Reason = fmt.Sprintf("La política %s deniega el tráfico desde %s.", result.DecidingPolicy, result.DecidingRule)
+	case result.Action == ActionAllowWithShadowDeny:
+		result.Verdict = VerdictAllowed
+		result.Reason = fmt.Sprintf("La política %s permite el tráfico desde %s, pero lo registra como si estuviera denegado (shadow deny).", result.DecidingPolicy, result.DecidingRule)
+	default:
+		result.Verdict = VerdictAllowed
+		result.Reason = fmt.Sprintf("La política %s permite el tráfico desde %s.", result.DecidingPolicy, result.DecidingRule)
+	}
+	if e.mtls.Mode == MTLSModePermissive {
+		result.Reason += " El mTLS está en modo PERMISSIVE: el destino también acepta tráfico en claro, al que no se aplican las MeshTrafficPermission."
+	}
+	return result
+}
+
+// selects indica si un targetRef selecciona un servicio.
+func (e *PermissionEngine) selects(ref TargetRef, service string) bool {
+	services, ok := e.selected[ref.key()]
+	if !ok {
+		services = e.resolver.SelectServices(ref)
+		e.selected[ref.key()] = services
+	}
+	return services[service]
+}
+
+// CanReach evalúa si el servicio from puede alcanzar al servicio to. Si env.Meshes no
+// acota la búsqueda a un único mesh, se usa el mesh en el que existen ambos servicios.
+func CanReach(ctx context.Context, env *Env, from, to string) (*Reachability, error) {
+	meshes, err := ResolveMeshes(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		engine   *PermissionEngine
+		from, to string
+	}
+	var candidates []candidate
+	var lastErr error
+	for _, mesh := range meshes {
+		engine, err := NewPermissionEngine(ctx, env.forMesh(mesh))
+		if err != nil {
+			return nil, err
+		}
+		fromService, err := engine.ResolveService(from)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		toService, err := engine.ResolveService(to)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		candidates = append(candidates, candidate{engine: engine, from: fromService, to: toService})
+	}
+
+	switch len(candidates) {
+	case 0:
+		if len(meshes) == 1 {
+			return nil, lastErr
+		}
+		return nil, fmt.Errorf("no hay ningún mesh en el que existan a la vez '%s' y '%s'", from, to)
+	case 1:
+		c := candidates[0]
+		return c.engine.Evaluate(c.from, c.to), nil
+	default:
+		var names []string
+		for _, c := range candidates {
+			names = append(names, c.engine.mesh)
+		}
+		return nil, fmt.Errorf("los servicios existen en varios meshes (%s); indica uno con --mesh", strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,195 @@
+// pkg/analysis/reachability_test.go
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// reachabilityResources son tres servicios y sus MeshTrafficPermission:
+//   - allow-all permite todo el tráfico del mesh;
+//   - deny-web (MeshService backend, más específica) deniega web -> backend;
+//   - db deniega a todo el mesh y, en una entrada más específica que se aplica después aunque
+//     se declare antes, permite web con shadow deny.
+const reachabilityResources = `
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: web-1, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 80, tags: {kuma.io/service: web}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: backend-1, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 3001, tags: {kuma.io/service: backend}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: db-1, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 5432, tags: {kuma.io/service: db}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: MeshTrafficPermission
+metadata: {name: allow-all, labels: {kuma.io/mesh: default}}
+spec:
+  targetRef: {kind: Mesh}
+  from:
+  - targetRef: {kind: Mesh}
+    default: {action: Allow}
+---
+apiVersion: kuma.io/v1alpha1
+kind: MeshTrafficPermission
+metadata: {name: deny-web, labels: {kuma.io/mesh: default}}
+spec:
+  targetRef: {kind: MeshService, name: backend}
+  from:
+  - targetRef: {kind: MeshService, name: web}
+    default: {action: Deny}
+---
+apiVersion: kuma.io/v1alpha1
+kind: MeshTrafficPermission
+metadata: {name: db, labels: {kuma.io/mesh: default}}
+spec:
+  targetRef: {kind: MeshService, name: db}
+  from:
+  - targetRef: {kind: MeshService, name: web}
+    default: {action: AllowWithShadowDeny}
+  - targetRef: {kind: Mesh}
+    default: {action: Deny}
+`
+
+// Especificaciones del Mesh default para cada estado de mTLS.
+const (
+	meshStrict     = `{mtls: {enabledBackend: ca-1, backends: [{name: ca-1, type: builtin}]}}`
+	meshPermissive = `{mtls: {enabledBackend: ca-1, backends: [{name: ca-1, type: builtin, mode: permissive}]}}`
+	meshNoMTLS     = `{}`
+)
+
+func newTestPermissionEngine(t *testing.T, meshSpec, resources string) *PermissionEngine {
+	t.Helper()
+	manifests := "apiVersion: kuma.io/v1alpha1\nkind: Mesh\nmetadata: {name: default}\nspec: " + meshSpec + "\n---\n" + resources
+	engine, err := NewPermissionEngine(context.Background(), &Env{Source: newFakeSource(t, manifests), Mesh: "default"})
+	if err != nil {
+		t.Fatalf("NewPermissionEngine: %v", err)
+	}
+	return engine
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		mesh     string
+		from, to string
+		verdict  Verdict
+		action   string
+		deciding string // política que decide, vacía si ninguna
+		steps    int
+	}{
+		{
+			name: "gana la política más específica", mesh: meshStrict, from: "web", to: "backend",
+			verdict: VerdictDenied, action: ActionDeny, deciding: "MeshTrafficPermission/deny-web", steps: 2,
+		},
+		{
+			name: "solo se aplica la regla del mesh", mesh: meshStrict, from: "db", to: "backend",
+			verdict: VerdictAllowed, action: ActionAllow, deciding: "MeshTrafficPermission/allow-all", steps: 1,
+		},
+		{
+			name: "dentro de una política gana la entrada más específica", mesh: meshStrict, from: "web", to: "db",
+			verdict: VerdictAllowed, action: ActionAllowWithShadowDeny, deciding: "MeshTrafficPermission/db", steps: 3,
+		},
+		{
+			name: "la regla Mesh de una política más específica deniega", mesh: meshStrict, from: "backend", to: "db",
+			verdict: VerdictDenied, action: ActionDeny, deciding: "MeshTrafficPermission/db", steps: 2,
+		},
+		{
+			name: "sin mTLS todo está permitido", mesh: meshNoMTLS, from: "web", to: "backend",
+			verdict: VerdictAllowed, steps: 2,
+		},
+		{
+			name: "en PERMISSIVE las políticas siguen decidiendo", mesh: meshPermissive, from: "web", to: "backend",
+			verdict: VerdictDenied, action: ActionDeny, deciding: "MeshTrafficPermission/deny-web", steps: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newTestPermissionEngine(t, tt.mesh, reachabilityResources).Evaluate(tt.from, tt.to)
+			if result.Verdict != tt.verdict || result.Action != tt.action {
+				t.Errorf("Evaluate(%s, %s) = %s (%q), se esperaba %s (%q): %s",
+					tt.from, tt.to, result.Verdict, result.Action, tt.verdict, tt.action, result.Reason)
+			}
+			deciding := ""
+			if result.DecidingPolicy != nil {
+				deciding = result.DecidingPolicy.String()
+			}
+			if deciding != tt.deciding {
+				t.Errorf("política que decide = %q, se esperaba %q", deciding, tt.deciding)
+			}
+			if len(result.Steps) != tt.steps {
+				t.Errorf("se aplican %d reglas, se esperaban %d: %+v", len(result.Steps), tt.steps, result.Steps)
+			}
+			if n := len(result.Steps); n > 0 && result.Action != "" && result.Steps[n-1].Action != result.Action {
+				t.Errorf("decide %q pero la última regla es %q", result.Action, result.Steps[n-1].Action)
+			}
+		})
+	}
+}
+
+func TestEvaluateReason(t *testing.T) {
+	tests := []struct {
+		name      string
+		mesh      string
+		resources string
+		verdict   Verdict
+		contains  []string
+		excludes  []string
+	}{
+		{
+			name:      "sin mTLS",
+			mesh:      meshNoMTLS,
+			resources: reachabilityResources,
+			verdict:   VerdictAllowed,
+			contains:  []string{"mTLS está desactivado"},
+			excludes:  []string{"PERMISSIVE"},
+		},
+		{
+			name:      "STRICT",
+			mesh:      meshStrict,
+			resources: reachabilityResources,
+			verdict:   VerdictDenied,
+			contains:  []string{"deniega el tráfico"},
+			excludes:  []string{"PERMISSIVE"},
+		},
+		{
+			name:      "PERMISSIVE",
+			mesh:      meshPermissive,
+			resources: reachabilityResources,
+			verdict:   VerdictDenied,
+			contains:  []string{"deniega el tráfico", "modo PERMISSIVE", "tráfico en claro"},
+		},
+		{
+			name:      "sin políticas se deniega por defecto",
+			mesh:      meshStrict,
+			resources: reachabilityResources[:strings.Index(reachabilityResources, "---\napiVersion: kuma.io/v1alpha1\nkind: MeshTrafficPermission")],
+			verdict:   VerdictDenied,
+			contains:  []string{"Ninguna MeshTrafficPermission permite este tráfico"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newTestPermissionEngine(t, tt.mesh, tt.resources).Evaluate("web", "backend")
+			if result.Verdict != tt.verdict {
+				t.Errorf("veredicto = %s, se esperaba %s: %s", result.Verdict, tt.verdict, result.Reason)
+			}
+			for _, text := range tt.contains {
+				if !strings.Contains(result.Reason, text) {
+					t.Errorf("el motivo %q no incluye %q", result.Reason, text)
+				}
+			}
+			for _, text := range tt.excludes {
+				if strings.Contains(result.Reason, text) {
+					t.Errorf("el motivo %q no debería incluir %q", result.Reason, text)
+				}
+			}
+		})
+	}
+}
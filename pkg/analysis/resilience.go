@@ -5,125 +5,109 @@ import (
 	"context"
 	"fmt"
 	"time"
-
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
 )
 
+func init() {
+	Register(NewAnalyzer("resilience", "Políticas de Resiliencia (Retries, Timeouts, etc.)", CategoryResilience, AnalyzeResilience))
+}
+
+// resilienceChecks son los tipos de política de resiliencia cuya cobertura se revisa.
+var resilienceChecks = []struct {
+	policy  ResourceType
+	rule    Rule
+	message string
+}{
+	{MeshRetryType, RuleServiceWithoutRetry, "El servicio no está cubierto por ninguna política de reintentos (MeshRetry)."},
+	{MeshTimeoutType, RuleServiceWithoutTimeout, "El servicio no está cubierto por ninguna política de timeouts (MeshTimeout)."},
+	{MeshCircuitBreakerType, RuleServiceWithoutCircuitBreaker, "El servicio no está cubierto por ninguna política de circuit breaker (MeshCircuitBreaker)."},
+}
+
+const resilienceTitle = "Análisis de Políticas de Resiliencia"
+
 // AnalyzeResilience revisa la cobertura de políticas como MeshRetry, MeshTimeout, etc.
-func AnalyzeResilience(client dynamic.Interface) (*ValidationResult, error) {
-	var findings []interface{}
+func AnalyzeResilience(ctx context.Context, env *Env) (*ValidationResult, error) {
+	var findings []Finding
+	var diagnostics []string
 
 	// 1. Obtener todos los servicios únicos desde los Dataplanes
-	allServices, err := getAllServices(client)
+	resolver, err := NewTargetResolver(ctx, env)
 	if err != nil {
+		if result, ok := skipIfNotInstalled(resilienceTitle, err); ok {
+			return result, nil
+		}
 		return nil, err
 	}
+	allServices := resolver.AllServices()
 
-	// 2. Analizar la cobertura para cada tipo de política de resiliencia
-	retryCoveredServices, err := getCoveredServices(client, "meshreries", "MeshRetry")
-	if err != nil {
-		fmt.Printf("Advertencia: no se pudo analizar MeshRetry: %v\n", err)
-	}
-	timeoutCoveredServices, err := getCoveredServices(client, "meshtimeouts", "MeshTimeout")
-	if err != nil {
-		fmt.Printf("Advertencia: no se pudo analizar MeshTimeout: %v\n", err)
-	}
-	breakerCoveredServices, err := getCoveredServices(client, "meshcircuitbreakers", "MeshCircuitBreaker")
-	if err != nil {
-		fmt.Printf("Advertencia: no se pudo analizar MeshCircuitBreaker: %v\n", err)
+	// 2. Analizar la cobertura para cada tipo de política de resiliencia. Un tipo que no se
+	// puede leer se omite en lugar de marcar todos los servicios como no cubiertos.
+	covered := make([]map[string]bool, len(resilienceChecks))
+	for i, check := range resilienceChecks {
+		covered[i], err = getCoveredServices(ctx, env, resolver, check.policy)
+		switch {
+		case IsNotInstalled(err):
+			findings = append(findings, notInstalledFinding(check.policy))
+		case err != nil:
+			diagnostics = append(diagnostics, fmt.Sprintf("no se pudo analizar %s: %v", check.policy.Kind, err))
+		}
 	}
 
 	// 3. Comparar y generar hallazgos
-	for service := range allServices {
-		if _, found := retryCoveredServices[service]; !found {
-			findings = append(findings, ResilienceFinding{
-				Level:      "WARN",
-				PolicyType: "MeshRetry",
-				Service:    service,
-				Message:    "El servicio no está cubierto por ninguna política de reintentos.",
-			})
-		}
-		if _, found := timeoutCoveredServices[service]; !found {
-			findings = append(findings, ResilienceFinding{
-				Level:      "WARN",
-				PolicyType: "MeshTimeout",
-				Service:    service,
-				Message:    "El servicio no está cubierto por ninguna política de timeouts.",
-			})
-		}
-		if _, found := breakerCoveredServices[service]; !found {
-			findings = append(findings, ResilienceFinding{
-				Level:      "WARN",
-				PolicyType: "MeshCircuitBreaker",
-				Service:    service,
-				Message:    "El servicio no está cubierto por ninguna política de circuit breaker.",
-			})
+	for _, service := range sortedKeys(allServices) {
+		for i, check := range resilienceChecks {
+			if covered[i] != nil && !covered[i][service] {
+				findings = append(findings, check.rule.Finding(serviceRef(env.Mesh, service), check.message))
+			}
 		}
 	}
 
 	if len(findings) == 0 {
-		findings = append(findings, ResilienceFinding{
-			Level:   "INFO",
-			Message: "Todos los servicios parecen tener políticas de resiliencia básicas aplicadas.",
-		})
+		findings = append(findings, RuleResilienceCovered.Finding(
+			ResourceRef{Kind: "Service", Mesh: env.Mesh, Name: "Global"},
+			"Todos los servicios parecen tener políticas de resiliencia básicas aplicadas.",
+		))
 	}
 
 	return &ValidationResult{
-		Title:       "Análisis de Políticas de Resiliencia",
+		Title:       resilienceTitle,
 		GeneratedAt: time.Now(),
 		Findings:    findings,
+		Diagnostics: diagnostics,
 	}, nil
 }
 
 // getCoveredServices es una función helper para obtener los servicios cubiertos por un tipo de política.
-func getCoveredServices(client dynamic.Interface, resourceName string, policyType string) (map[string]bool, error) {
-	gvr := schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: resourceName}
-	policies, err := client.Resource(gvr).List(context.TODO(), v1.ListOptions{})
+// Las entradas 'to' cubren los servicios de destino que seleccionan; las entradas 'from'
+// cubren los servicios que selecciona el 'spec.targetRef' de la política (los que reciben el tráfico).
+// Una política cuyo 'spec.targetRef' no selecciona ningún Dataplane no configura ningún proxy,
+// así que no cubre nada aunque sus entradas 'to' seleccionen servicios.
+func getCoveredServices(ctx context.Context, env *Env, resolver *TargetResolver, policyType ResourceType) (map[string]bool, error) {
+	policies, err := env.Source.List(ctx, policyType, "")
 	if err != nil {
 		return nil, err
 	}
 
 	coveredServices := make(map[string]bool)
-	for _, policy := range policies.Items {
-		to, found, _ := unstructured.NestedSlice(policy.Object, "spec", "to")
-		if found {
-			for _, toItem := range to {
-				rule, _ := toItem.(map[string]interface{})
-				targetRefName, _, _ := unstructured.NestedString(rule, "targetRef", "name")
-				if targetRefName != "" {
-					// Si la política apunta a '*', marcamos todos los servicios (aunque es una simplificación).
-					// Por ahora, solo manejamos referencias directas a servicios.
-					coveredServices[targetRefName] = true
-				}
+	for _, policy := range filterByMesh(policies, env.Mesh) {
+		top := policyTargetRef(policy)
+		if len(resolver.SelectProxies(top)) == 0 {
+			continue
+		}
+		for _, rule := range policyRules(policy, "to") {
+			for service := range resolver.SelectServices(rule.TargetRef) {
+				coveredServices[service] = true
+			}
+		}
+		if len(policyRules(policy, "from")) > 0 {
+			for service := range resolver.SelectServices(top) {
+				coveredServices[service] = true
 			}
 		}
 	}
 	return coveredServices, nil
 }
 
-// getAllServices es una función helper para obtener un mapa de todos los servicios únicos del mesh.
-func getAllServices(client dynamic.Interface) (map[string]bool, error) {
-	dataplaneGVR := schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: "dataplanes"}
-	dataplanes, err := client.Resource(dataplaneGVR).List(context.TODO(), v1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("error al listar Dataplanes para obtener servicios: %w", err)
-	}
-
-	allServices := make(map[string]bool)
-	for _, dp := range dataplanes.Items {
-		inbounds, found, _ := unstructured.NestedSlice(dp.Object, "spec", "networking", "inbound")
-		if found {
-			for _, inboundItem := range inbounds {
-				inboundMap, _ := inboundItem.(map[string]interface{})
-				serviceName, _, _ := unstructured.NestedString(inboundMap, "tags", "kuma.io/service")
-				if serviceName != "" {
-					allServices[serviceName] = true
-				}
-			}
-		}
-	}
-	return allServices, nil
+// serviceRef construye la referencia de un servicio de Kuma (valor de la etiqueta kuma.io/service).
+func serviceRef(mesh, service string) ResourceRef {
+	return ResourceRef{Kind: "Service", Mesh: mesh, Name: service}
 }
@@ -0,0 +1,80 @@
+// pkg/analysis/resilience_test.go
+package analysis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// resilienceDataplanes son dos servicios, web y backend, en el namespace demo.
+const resilienceDataplanes = `
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: web-1, namespace: demo, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 80, tags: {kuma.io/service: web_demo_svc_80, k8s.kuma.io/service-name: web, k8s.kuma.io/namespace: demo}}]}}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: backend-1, namespace: demo, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 3001, tags: {kuma.io/service: backend_demo_svc_3001, k8s.kuma.io/service-name: backend, k8s.kuma.io/namespace: demo}}]}}
+`
+
+func TestGetCoveredServices(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   []string
+	}{
+		{
+			name: "to desde todo el mesh",
+			policy: `
+spec:
+  targetRef: {kind: Mesh}
+  to:
+  - targetRef: {kind: MeshService, name: backend}
+    default: {http: {requestTimeout: 5s}}`,
+			want: []string{"backend_demo_svc_3001"},
+		},
+		{
+			name: "to desde un targetRef que no selecciona ningún proxy",
+			policy: `
+spec:
+  targetRef: {kind: MeshService, name: missing}
+  to:
+  - targetRef: {kind: Mesh}
+    default: {http: {requestTimeout: 5s}}`,
+			want: []string{},
+		},
+		{
+			name: "from cubre los servicios del targetRef",
+			policy: `
+spec:
+  targetRef: {kind: MeshService, name: web}
+  from:
+  - targetRef: {kind: Mesh}
+    default: {http: {requestTimeout: 5s}}`,
+			want: []string{"web_demo_svc_80"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifests := resilienceDataplanes + `---
+apiVersion: kuma.io/v1alpha1
+kind: MeshTimeout
+metadata: {name: timeout, namespace: demo, labels: {kuma.io/mesh: default}}` + tt.policy
+			env := &Env{Source: newFakeSource(t, manifests), Mesh: "default"}
+			resolver, err := NewTargetResolver(context.Background(), env)
+			if err != nil {
+				t.Fatalf("NewTargetResolver: %v", err)
+			}
+			covered, err := getCoveredServices(context.Background(), env, resolver, MeshTimeoutType)
+			if err != nil {
+				t.Fatalf("getCoveredServices: %v", err)
+			}
+			if got := sortedKeys(covered); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("servicios cubiertos = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
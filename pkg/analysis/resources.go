@@ -0,0 +1,116 @@
+// pkg/analysis/resources.go
+package analysis
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+// ResourceType describe un tipo de recurso que consultan los analizadores.
+type ResourceType struct {
+	Kind       string
+	GVR        schema.GroupVersionResource
+	Namespaced bool
+}
+
+func kumaResource(kind, resource string, namespaced bool) ResourceType {
+	return ResourceType{
+		Kind:       kind,
+		GVR:        schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: resource},
+		Namespaced: namespaced,
+	}
+}
+
+func kubeResource(group, version, kind, resource string, namespaced bool) ResourceType {
+	return ResourceType{
+		Kind:       kind,
+		GVR:        schema.GroupVersionResource{Group: group, Version: version, Resource: resource},
+		Namespaced: namespaced,
+	}
+}
+
+// Catálogo de recursos de Kuma utilizados por los analizadores.
+var (
+	MeshType                  = kumaResource("Mesh", "meshes", false)
+	DataplaneType             = kumaResource("Dataplane", "dataplanes", true)
+	DataplaneInsightType      = kumaResource("DataplaneInsight", "dataplaneinsights", true)
+	MeshTrafficPermissionType = kumaResource("MeshTrafficPermission", "meshtrafficpermissions", true)
+	MeshRetryType             = kumaResource("MeshRetry", "meshretries", true)
+	MeshTimeoutType           = kumaResource("MeshTimeout", "meshtimeouts", true)
+	MeshCircuitBreakerType    = kumaResource("MeshCircuitBreaker", "meshcircuitbreakers", true)
+	MeshLogType               = kumaResource("MeshLog", "meshlogs", true)
+	MeshMetricType            = kumaResource("MeshMetric", "meshmetrics", true)
+	MeshTraceType             = kumaResource("MeshTrace", "meshtraces", true)
+	MeshGatewayType           = kumaResource("MeshGateway", "meshgateways", false)
+	MeshHealthCheckType       = kumaResource("MeshHealthCheck", "meshhealthchecks", true)
+	MeshRateLimitType         = kumaResource("MeshRateLimit", "meshratelimits", true)
+	MeshFaultInjectionType    = kumaResource("MeshFaultInjection", "meshfaultinjections", true)
+	MeshLoadBalancingType     = kumaResource("MeshLoadBalancingStrategy", "meshloadbalancingstrategies", true)
+	MeshHTTPRouteType         = kumaResource("MeshHTTPRoute", "meshhttproutes", true)
+	MeshTCPRouteType          = kumaResource("MeshTCPRoute", "meshtcproutes", true)
+	MeshProxyPatchType        = kumaResource("MeshProxyPatch", "meshproxypatches", true)
+	MeshAccessLogType         = kumaResource("MeshAccessLog", "meshaccesslogs", true)
+	MeshTLSType               = kumaResource("MeshTLS", "meshtlses", true)
+	ExternalServiceType       = kumaResource("ExternalService", "externalservices", false)
+	MeshExternalServiceType   = kumaResource("MeshExternalService", "meshexternalservices", true)
+)
+
+// Recursos de Kubernetes que leen los análisis del control plane y de inyección. No forman parte de
+// KnownResourceTypes: no son CRDs de Kuma y en Universal no existen, así que un origen que no
+// los tenga los trata como no instalados.
+var (
+	NamespaceType                      = kubeResource("", "v1", "Namespace", "namespaces", false)
+	DeploymentType                     = kubeResource("apps", "v1", "Deployment", "deployments", true)
+	PodType                            = kubeResource("", "v1", "Pod", "pods", true)
+	ServiceType                        = kubeResource("", "v1", "Service", "services", true)
+	EndpointsType                      = kubeResource("", "v1", "Endpoints", "endpoints", true)
+	LeaseType                          = kubeResource("coordination.k8s.io", "v1", "Lease", "leases", true)
+	MutatingWebhookConfigurationType   = kubeResource("admissionregistration.k8s.io", "v1", "MutatingWebhookConfiguration", "mutatingwebhookconfigurations", false)
+	ValidatingWebhookConfigurationType = kubeResource("admissionregistration.k8s.io", "v1", "ValidatingWebhookConfiguration", "validatingwebhookconfigurations", false)
+)
+
+// KnownResourceTypes devuelve todos los tipos de recurso del catálogo.
+func KnownResourceTypes() []ResourceType {
+	return []ResourceType{
+		MeshType,
+		DataplaneType,
+		DataplaneInsightType,
+		MeshTrafficPermissionType,
+		MeshRetryType,
+		MeshTimeoutType,
+		MeshCircuitBreakerType,
+		MeshLogType,
+		MeshMetricType,
+		MeshTraceType,
+		MeshGatewayType,
+		MeshHealthCheckType,
+		MeshRateLimitType,
+		MeshFaultInjectionType,
+		MeshLoadBalancingType,
+		MeshHTTPRouteType,
+		MeshTCPRouteType,
+		MeshProxyPatchType,
+		MeshAccessLogType,
+		MeshTLSType,
+		ExternalServiceType,
+		MeshExternalServiceType,
+	}
+}
+
+// PolicyTypes son los tipos de política de Kuma basados en targetRef que revisan los análisis
+// transversales (explain, políticas huérfanas...), en el orden en que se muestran.
+var PolicyTypes = []ResourceType{
+	MeshTrafficPermissionType,
+	MeshTimeoutType,
+	MeshRetryType,
+	MeshCircuitBreakerType,
+	MeshHealthCheckType,
+	MeshRateLimitType,
+	MeshFaultInjectionType,
+	MeshLoadBalancingType,
+	MeshLogType,
+	MeshMetricType,
+	MeshTraceType,
+	MeshAccessLogType,
+	MeshTLSType,
+	MeshHTTPRouteType,
+	MeshTCPRouteType,
+	MeshProxyPatchType,
+}
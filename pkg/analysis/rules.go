@@ -0,0 +1,373 @@
+// pkg/analysis/rules.go
+package analysis
+
+// Rule describe una regla de validación con un ID estable. Los IDs no deben reutilizarse
+// ni renumerarse: los usuarios los referencian en filtros, supresiones y pipelines de CI.
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Remediation string
+}
+
+// Finding crea un hallazgo de esta regla para el recurso indicado.
+func (r Rule) Finding(resource ResourceRef, message string) Finding {
+	return Finding{
+		RuleID:      r.ID,
+		Severity:    r.Severity,
+		Resource:    resource,
+		Message:     message,
+		Remediation: r.Remediation,
+	}
+}
+
+// --- Dataplanes (KD-DP) ---
+var (
+	RuleDataplaneOnline   = Rule{ID: "KD-DP-001", Severity: SeverityInfo}
+	RuleDataplaneDegraded = Rule{
+		ID:          "KD-DP-002",
+		Severity:    SeverityWarn,
+		Remediation: "Revisa los readiness probes y los logs de la aplicación en los puertos que no están 'ready'.",
+	}
+	RuleDataplaneOffline = Rule{
+		ID:          "KD-DP-003",
+		Severity:    SeverityAlert,
+		Remediation: "Revisa el estado del pod y los logs del contenedor kuma-sidecar (kubectl logs <pod> -c kuma-sidecar).",
+	}
+	RuleDataplaneNoInbounds   = Rule{ID: "KD-DP-004", Severity: SeverityInfo}
+	RuleDataplaneDisconnected = Rule{
+		ID:          "KD-DP-005",
+		Severity:    SeverityAlert,
+		Remediation: "Revisa los logs del contenedor kuma-sidecar y la conectividad con el puerto xDS del control plane (5678).",
+	}
+	RuleDataplaneNeverConnected = Rule{
+		ID:          "KD-DP-006",
+		Severity:    SeverityWarn,
+		Remediation: "Comprueba que kuma-dp arranca y que puede autenticarse contra el control plane (token del dataplane o service account).",
+	}
+	RuleDataplaneStaleSubscription = Rule{
+		ID:          "KD-DP-007",
+		Severity:    SeverityWarn,
+		Remediation: "Suele quedar tras reiniciar una instancia del control plane; si persiste, reinicia el proxy para que el insight se regenere.",
+	}
+	RuleDataplaneCertExpiring = Rule{
+		ID:          "KD-DP-008",
+		Severity:    SeverityWarn,
+		Remediation: "El certificado debería haberse rotado ya: revisa el backend de CA del Mesh y los logs del control plane.",
+	}
+	RuleDataplaneCertExpired = Rule{
+		ID:          "KD-DP-009",
+		Severity:    SeverityAlert,
+		Remediation: "El proxy no puede establecer conexiones mTLS: revisa el backend de CA del Mesh y reinicia el proxy.",
+	}
+	RuleDataplaneVersionIncompatible = Rule{
+		ID:          "KD-DP-010",
+		Severity:    SeverityWarn,
+		Remediation: "Actualiza la imagen del sidecar (kuma-dp y Envoy) a una versión compatible con el control plane.",
+	}
+	RuleDataplaneStale = Rule{
+		ID:          "KD-DP-011",
+		Severity:    SeverityWarn,
+		Remediation: "El proxy ya no existe: borra el Dataplane con el comando indicado para que deje de contar como Offline.",
+	}
+)
+
+// --- Versiones del control plane y los Dataplanes (KD-VER) ---
+var (
+	RuleDataplaneVersionCurrent = Rule{ID: "KD-VER-001", Severity: SeverityInfo}
+	RuleDataplaneVersionBehind  = Rule{
+		ID:          "KD-VER-002",
+		Severity:    SeverityWarn,
+		Remediation: "Reinicia los workloads de estos Dataplanes (p. ej. kubectl rollout restart) para que el inyector les ponga el sidecar de la versión del control plane.",
+	}
+	RuleDataplaneVersionUnsupported = Rule{
+		ID:          "KD-VER-003",
+		Severity:    SeverityAlert,
+		Remediation: "Reinicia estos workloads cuanto antes: el control plane no garantiza la compatibilidad con versiones de kuma-dp fuera del desfase soportado.",
+	}
+	RuleDataplaneVersionAhead = Rule{
+		ID:          "KD-VER-004",
+		Severity:    SeverityWarn,
+		Remediation: "Kuma no admite data planes más nuevos que el control plane: actualiza primero el control plane o fija la imagen del sidecar a su versión.",
+	}
+	RuleDataplaneVersionUnknown = Rule{ID: "KD-VER-005", Severity: SeverityInfo}
+)
+
+// --- Inyección de sidecars (KD-INJ) ---
+var (
+	RulePodWithoutSidecar = Rule{
+		ID:          "KD-INJ-001",
+		Severity:    SeverityWarn,
+		Remediation: "Reinicia el workload (kubectl rollout restart) para que el webhook del control plane inyecte el sidecar.",
+	}
+	RulePodInjectionDisabled = Rule{ID: "KD-INJ-002", Severity: SeverityInfo}
+	// KD-INJ-003 (Dataplane sin pod) ya no se usa: lo cubre KD-DP-011 (RuleDataplaneStale).
+	RuleInjectionCoverage    = Rule{ID: "KD-INJ-004", Severity: SeverityInfo}
+	RuleNoInjectedNamespaces = Rule{ID: "KD-INJ-005", Severity: SeverityInfo}
+)
+
+// --- Consistencia de inbounds por servicio (KD-SVC) ---
+var (
+	RuleServicePortMismatch = Rule{
+		ID:          "KD-SVC-001",
+		Severity:    SeverityWarn,
+		Remediation: "Unifica el puerto del contenedor (o el targetPort del Service) en todas las réplicas del servicio.",
+	}
+	RuleServiceProtocolMismatch = Rule{
+		ID:          "KD-SVC-002",
+		Severity:    SeverityAlert,
+		Remediation: "Declara el mismo protocolo en todas las réplicas (appProtocol del Service en Kubernetes o la etiqueta kuma.io/protocol del inbound en Universal).",
+	}
+	RuleServiceProtocolPartial = Rule{
+		ID:          "KD-SVC-003",
+		Severity:    SeverityWarn,
+		Remediation: "Añade kuma.io/protocol a los inbounds que no la tienen: Kuma los trata como tcp.",
+	}
+	RuleServiceProtocolUndeclared = Rule{
+		ID:          "KD-SVC-004",
+		Severity:    SeverityInfo,
+		Remediation: "Si el servicio habla HTTP o gRPC, declara el protocolo para habilitar retries, rutas HTTP y métricas L7.",
+	}
+	RuleServiceVersionTagPartial = Rule{
+		ID:          "KD-SVC-005",
+		Severity:    SeverityWarn,
+		Remediation: "Añade la etiqueta version a todas las réplicas: los selectores por versión (MeshServiceSubset, MeshHTTPRoute) no ven las que no la tienen.",
+	}
+	RuleServiceNameCollision = Rule{
+		ID:          "KD-SVC-006",
+		Severity:    SeverityWarn,
+		Remediation: "Da a cada servicio un kuma.io/service único en el mesh; si son el mismo servicio, despliégalo en un solo namespace.",
+	}
+	RuleServiceNameAmbiguous = Rule{
+		ID:          "KD-SVC-007",
+		Severity:    SeverityInfo,
+		Remediation: "Indica el namespace en los targetRef MeshService que usen este nombre para no seleccionar los Services de todos los namespaces.",
+	}
+	RuleServicesConsistent = Rule{ID: "KD-SVC-008", Severity: SeverityInfo}
+)
+
+// --- Protocolo de los servicios (KD-PROTO) ---
+var (
+	RuleProtocolTagMismatch = Rule{
+		ID:          "KD-PROTO-001",
+		Severity:    SeverityWarn,
+		Remediation: "Reinicia los pods del servicio para que Kuma regenere sus Dataplanes con el protocolo del Service, o corrige el appProtocol si el que está mal es el Service.",
+	}
+	RuleAppProtocolUnsupported = Rule{
+		ID:          "KD-PROTO-002",
+		Severity:    SeverityWarn,
+		Remediation: "Usa en appProtocol (o en la anotación <puerto>.service.kuma.io/protocol) uno de los protocolos que reconoce Kuma: http, http2, grpc, kafka o tcp.",
+	}
+	RuleHTTPPolicyOnTCPService = Rule{
+		ID:          "KD-PROTO-003",
+		Severity:    SeverityWarn,
+		Remediation: "Declara appProtocol: http (o http2/grpc) en el puerto del Service o elimina la configuración HTTP: Kuma solo la aplica a servicios HTTP.",
+	}
+	RuleProtocolsConsistent = Rule{ID: "KD-PROTO-004", Severity: SeverityInfo}
+)
+
+// --- MeshTrafficPermission (KD-MTP) ---
+var (
+	RuleServiceWithoutTrafficPermission = Rule{
+		ID:          "KD-MTP-001",
+		Severity:    SeverityAlert,
+		Remediation: "Crea una MeshTrafficPermission que seleccione este servicio y defina qué orígenes pueden acceder a él.",
+	}
+	RuleTrafficPermissionFromAny = Rule{
+		ID:          "KD-MTP-002",
+		Severity:    SeverityInfo,
+		Remediation: "Restringe la sección 'from' a los servicios que realmente necesitan acceso.",
+	}
+	RuleAllServicesHaveTrafficPermission = Rule{ID: "KD-MTP-003", Severity: SeverityInfo}
+	RuleTrafficPermissionShadowed        = Rule{
+		ID:          "KD-MTP-004",
+		Severity:    SeverityWarn,
+		Remediation: "Elimina la política anulada o integra sus reglas en la que la anula.",
+	}
+	RuleTrafficPermissionDuplicate = Rule{
+		ID:          "KD-MTP-005",
+		Severity:    SeverityWarn,
+		Remediation: "Elimina una de las dos políticas; mantenerlas duplicadas obliga a cambiar ambas en cada modificación.",
+	}
+	RuleTrafficPermissionConflict = Rule{
+		ID:          "KD-MTP-006",
+		Severity:    SeverityWarn,
+		Remediation: "Deja una sola acción para ese origen, o haz más específico el targetRef de la política que debe prevalecer en lugar de depender del orden alfabético de los nombres.",
+	}
+	RuleTrafficPermissionsConsistent = Rule{ID: "KD-MTP-007", Severity: SeverityInfo}
+)
+
+// --- Políticas huérfanas (KD-POL) ---
+var (
+	RulePolicyOrphaned = Rule{
+		ID:          "KD-POL-001",
+		Severity:    SeverityWarn,
+		Remediation: "Elimina la política o actualiza su targetRef al nombre o etiquetas actuales del servicio (p. ej. tras un renombrado).",
+	}
+	RulePolicyEntryOrphaned = Rule{
+		ID:          "KD-POL-002",
+		Severity:    SeverityWarn,
+		Remediation: "Elimina la entrada o actualiza su targetRef al nombre o etiquetas actuales del servicio.",
+	}
+	RuleNoOrphanedPolicies  = Rule{ID: "KD-POL-003", Severity: SeverityInfo}
+	RuleOrphansNotEvaluated = Rule{ID: "KD-POL-004", Severity: SeverityInfo}
+)
+
+// --- mTLS (KD-MTLS) ---
+var (
+	RuleMeshNotFound = Rule{
+		ID:          "KD-MTLS-001",
+		Severity:    SeverityAlert,
+		Remediation: "Verifica que el Mesh exista (kubectl get meshes) y que tengas permisos para leerlo.",
+	}
+	RuleMTLSDisabled = Rule{
+		ID:          "KD-MTLS-002",
+		Severity:    SeverityAlert,
+		Remediation: "Define un backend en spec.mtls.backends y actívalo con spec.mtls.enabledBackend.",
+	}
+	RuleMTLSEnabled    = Rule{ID: "KD-MTLS-003", Severity: SeverityInfo}
+	RuleMTLSNoBackends = Rule{
+		ID:          "KD-MTLS-004",
+		Severity:    SeverityAlert,
+		Remediation: "Añade la definición del backend en spec.mtls.backends.",
+	}
+	RuleMTLSBackendUndefined = Rule{
+		ID:          "KD-MTLS-005",
+		Severity:    SeverityAlert,
+		Remediation: "Haz que spec.mtls.enabledBackend coincida con el nombre de uno de los backends definidos.",
+	}
+	RuleTrafficPermissionWithoutMTLS = Rule{
+		ID:          "KD-MTLS-006",
+		Severity:    SeverityWarn,
+		Remediation: "Activa mTLS en el Mesh (spec.mtls.enabledBackend) para que se apliquen las MeshTrafficPermission.",
+	}
+	RuleTrafficPermissionInvalidAction = Rule{
+		ID:          "KD-MTLS-007",
+		Severity:    SeverityAlert,
+		Remediation: "Usa una de las acciones de MeshTrafficPermission: Allow, Deny o AllowWithShadowDeny.",
+	}
+)
+
+// --- Resiliencia (KD-RES) ---
+var (
+	RuleServiceWithoutRetry = Rule{
+		ID:          "KD-RES-001",
+		Severity:    SeverityWarn,
+		Remediation: "Crea una MeshRetry que tenga este servicio como destino.",
+	}
+	RuleServiceWithoutTimeout = Rule{
+		ID:          "KD-RES-002",
+		Severity:    SeverityWarn,
+		Remediation: "Crea una MeshTimeout que tenga este servicio como destino.",
+	}
+	RuleServiceWithoutCircuitBreaker = Rule{
+		ID:          "KD-RES-003",
+		Severity:    SeverityWarn,
+		Remediation: "Crea una MeshCircuitBreaker que tenga este servicio como destino.",
+	}
+	RuleResilienceCovered = Rule{ID: "KD-RES-004", Severity: SeverityInfo}
+)
+
+// --- Observabilidad (KD-OBS) ---
+var (
+	RuleMeshLogMissing = Rule{
+		ID:          "KD-OBS-001",
+		Severity:    SeverityWarn,
+		Remediation: "Crea una política MeshLog con un backend (file, tcp u OpenTelemetry) para capturar los logs de acceso.",
+	}
+	RuleMeshLogFound      = Rule{ID: "KD-OBS-002", Severity: SeverityInfo}
+	RuleMeshMetricMissing = Rule{
+		ID:          "KD-OBS-003",
+		Severity:    SeverityWarn,
+		Remediation: "Crea una política MeshMetric para exponer las métricas de los proxies a Prometheus.",
+	}
+	RuleMeshMetricFound  = Rule{ID: "KD-OBS-004", Severity: SeverityInfo}
+	RuleMeshTraceMissing = Rule{
+		ID:          "KD-OBS-005",
+		Severity:    SeverityWarn,
+		Remediation: "Crea una política MeshTrace con un backend (Zipkin, Datadog u OpenTelemetry).",
+	}
+	RuleMeshTraceFound = Rule{ID: "KD-OBS-006", Severity: SeverityInfo}
+)
+
+// --- Control plane (KD-CP) ---
+var (
+	RuleControlPlaneHealthy     = Rule{ID: "KD-CP-001", Severity: SeverityInfo}
+	RuleControlPlaneUnavailable = Rule{
+		ID:          "KD-CP-002",
+		Severity:    SeverityAlert,
+		Remediation: "Revisa los eventos y los logs del control plane (kubectl -n kuma-system describe deployment kuma-control-plane; kubectl -n kuma-system logs deploy/kuma-control-plane).",
+	}
+	RuleControlPlaneDegraded = Rule{
+		ID:          "KD-CP-003",
+		Severity:    SeverityWarn,
+		Remediation: "Revisa por qué las réplicas restantes no están listas (kubectl -n kuma-system get pods -l app=kuma-control-plane).",
+	}
+	RuleControlPlaneRestarts = Rule{
+		ID:          "KD-CP-004",
+		Severity:    SeverityWarn,
+		Remediation: "Revisa los logs del contenedor anterior (kubectl logs <pod> -n kuma-system --previous); un OOMKilled indica que hay que subir el límite de memoria.",
+	}
+	RuleControlPlaneNoEndpoints = Rule{
+		ID:          "KD-CP-005",
+		Severity:    SeverityAlert,
+		Remediation: "Comprueba que el selector del Service kuma-control-plane coincide con las etiquetas de los pods y que estos pasan su readiness probe.",
+	}
+	RuleControlPlaneEndpointsNotReady = Rule{
+		ID:          "KD-CP-006",
+		Severity:    SeverityWarn,
+		Remediation: "Revisa la readiness probe de los pods del control plane que no están listos.",
+	}
+	RuleWebhookCABundleInvalid = Rule{
+		ID:          "KD-CP-007",
+		Severity:    SeverityAlert,
+		Remediation: "Vuelve a instalar el control plane (helm upgrade o kumactl install control-plane) para regenerar el caBundle, o revisa el Certificate de cert-manager que lo inyecta.",
+	}
+	RuleWebhookCAExpired = Rule{
+		ID:          "KD-CP-008",
+		Severity:    SeverityAlert,
+		Remediation: "Renueva el certificado de los webhooks del control plane y actualiza el caBundle de sus configuraciones.",
+	}
+	RuleWebhookCAExpiring = Rule{
+		ID:          "KD-CP-009",
+		Severity:    SeverityWarn,
+		Remediation: "Planifica la renovación del certificado de los webhooks del control plane antes de que caduque.",
+	}
+	RuleControlPlaneNoWebhooks = Rule{
+		ID:          "KD-CP-010",
+		Severity:    SeverityWarn,
+		Remediation: "Reinstala el control plane para recrear sus MutatingWebhookConfiguration y ValidatingWebhookConfiguration.",
+	}
+	RuleControlPlaneNoLeader = Rule{
+		ID:          "KD-CP-011",
+		Severity:    SeverityAlert,
+		Remediation: "Revisa los logs del control plane en busca de errores de leader election y los permisos sobre leases en kuma-system.",
+	}
+	RuleControlPlaneLeaderStale = Rule{
+		ID:          "KD-CP-012",
+		Severity:    SeverityWarn,
+		Remediation: "Si persiste más allá de la duración del lease, reinicia el Deployment del control plane para forzar una nueva elección.",
+	}
+	RuleControlPlaneLeader = Rule{ID: "KD-CP-013", Severity: SeverityInfo}
+	RuleWebhooksOK         = Rule{ID: "KD-CP-014", Severity: SeverityInfo}
+)
+
+// --- CRDs de Kuma (KD-CRD) ---
+var (
+	RuleCRDNotInstalled = Rule{
+		ID:          "KD-CRD-001",
+		Severity:    SeverityWarn,
+		Remediation: "Instala o actualiza los CRDs de Kuma (kumactl install crds | kubectl apply -f -, o el chart de Helm) con la misma versión que el control plane.",
+	}
+	RuleCRDUnexpectedVersion = Rule{
+		ID:          "KD-CRD-002",
+		Severity:    SeverityWarn,
+		Remediation: "Verifica que los CRDs correspondan a la versión de Kuma instalada; kuma-doctor usará la versión que sirve el clúster.",
+	}
+	RuleCRDUnknown       = Rule{ID: "KD-CRD-003", Severity: SeverityInfo}
+	RuleCRDsOK           = Rule{ID: "KD-CRD-004", Severity: SeverityInfo}
+	RuleKumaNotInstalled = Rule{
+		ID:          "KD-CRD-005",
+		Severity:    SeverityAlert,
+		Remediation: "Instala Kuma en el clúster o revisa que --context apunte al clúster correcto.",
+	}
+)
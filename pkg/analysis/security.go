@@ -6,54 +6,61 @@ import (
 	"fmt"
 	"time"
 
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
 )
 
+func init() {
+	Register(NewAnalyzer("mtls", "Configuración de mTLS (Seguridad)", CategorySecurity, AnalyzeMTLS))
+}
+
+const mtlsTitle = "Análisis de Configuración mTLS"
+
+// validTrafficActions son las acciones que admite 'default.action' en MeshTrafficPermission.
+var validTrafficActions = map[string]bool{ActionAllow: true, ActionDeny: true, ActionAllowWithShadowDeny: true}
+
 // AnalyzeMTLS revisa la configuración de mTLS en el Mesh y las políticas asociadas.
-func AnalyzeMTLS(client dynamic.Interface) (*ValidationResult, error) {
-	var findings []interface{}
+func AnalyzeMTLS(ctx context.Context, env *Env) (*ValidationResult, error) {
+	var findings []Finding
 
-	// Asumimos que el mesh a revisar se llama 'default'.
-	// Una mejora futura sería permitir al usuario especificar el mesh.
-	meshName := "default"
-	meshGVR := schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: "meshes"}
+	// El runner fija el mesh a analizar; si se invoca directamente, revisamos el mesh por defecto.
+	meshName := env.Mesh
+	if meshName == "" {
+		meshName = defaultMesh
+	}
+	meshRef := ResourceRef{Kind: "Mesh", Mesh: meshName, Name: meshName}
 
-	mesh, err := client.Resource(meshGVR).Get(context.TODO(), meshName, v1.GetOptions{})
+	mesh, err := env.Source.Get(ctx, MeshType, "", meshName)
 	if err != nil {
-		findings = append(findings, MTLSFinding{
-			Level:    "ALERT",
-			Message:  fmt.Sprintf("No se pudo obtener el Mesh '%s'. Error: %v", meshName, err),
-			Resource: meshName,
-		})
-		return &ValidationResult{Title: "Análisis de Configuración mTLS", Findings: findings}, nil
+		if result, ok := skipIfNotInstalled(mtlsTitle, err); ok {
+			return result, nil
+		}
+		findings = append(findings, RuleMeshNotFound.Finding(
+			meshRef,
+			fmt.Sprintf("No se pudo obtener el Mesh '%s'. Error: %v", meshName, err),
+		))
+		return &ValidationResult{Title: mtlsTitle, GeneratedAt: time.Now(), Findings: findings}, nil
 	}
 
 	// 1. Verificar si mTLS está habilitado en el Mesh
 	enabledBackend, backendFound, _ := unstructured.NestedString(mesh.Object, "spec", "mtls", "enabledBackend")
 	if !backendFound || enabledBackend == "" {
-		findings = append(findings, MTLSFinding{
-			Level:    "ALERT",
-			Message:  "mTLS está DESACTIVADO para este mesh. El tráfico entre servicios no está cifrado.",
-			Resource: meshName,
-		})
+		findings = append(findings, RuleMTLSDisabled.Finding(
+			meshRef,
+			"mTLS está DESACTIVADO para este mesh. El tráfico entre servicios no está cifrado.",
+		))
 	} else {
-		findings = append(findings, MTLSFinding{
-			Level:    "INFO",
-			Message:  fmt.Sprintf("mTLS está ACTIVADO con el backend '%s'.", enabledBackend),
-			Resource: meshName,
-		})
+		findings = append(findings, RuleMTLSEnabled.Finding(
+			meshRef,
+			fmt.Sprintf("mTLS está ACTIVADO con el backend '%s'.", enabledBackend),
+		))
 
 		// 2. Verificar que el backend habilitado esté definido en la lista de backends
 		backends, backendsFound, _ := unstructured.NestedSlice(mesh.Object, "spec", "mtls", "backends")
 		if !backendsFound || len(backends) == 0 {
-			findings = append(findings, MTLSFinding{
-				Level:    "ALERT",
-				Message:  fmt.Sprintf("El backend mTLS '%s' está habilitado, pero no se ha definido ninguna configuración de backends.", enabledBackend),
-				Resource: meshName,
-			})
+			findings = append(findings, RuleMTLSNoBackends.Finding(
+				meshRef,
+				fmt.Sprintf("El backend mTLS '%s' está habilitado, pero no se ha definido ninguna configuración de backends.", enabledBackend),
+			))
 		} else {
 			isBackendDefined := false
 			for _, backendItem := range backends {
@@ -65,54 +72,45 @@ func AnalyzeMTLS(client dynamic.Interface) (*ValidationResult, error) {
 				}
 			}
 			if !isBackendDefined {
-				findings = append(findings, MTLSFinding{
-					Level:    "ALERT",
-					Message:  fmt.Sprintf("El backend mTLS '%s' está habilitado, pero no se encuentra en la lista de backends definidos.", enabledBackend),
-					Resource: meshName,
-				})
+				findings = append(findings, RuleMTLSBackendUndefined.Finding(
+					meshRef,
+					fmt.Sprintf("El backend mTLS '%s' está habilitado, pero no se encuentra en la lista de backends definidos.", enabledBackend),
+				))
 			}
 		}
 	}
 
-	// 3. Revisar MeshTrafficPermissions para ver si fuerzan mTLS
-	mtpGVR := schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: "meshtrafficpermissions"}
-	policies, err := client.Resource(mtpGVR).List(context.TODO(), v1.ListOptions{})
-	if err != nil {
+	// 3. Revisar las acciones de las MeshTrafficPermission y si el mTLS permite aplicarlas
+	policies, err := env.Source.List(ctx, MeshTrafficPermissionType, "")
+	if IsNotInstalled(err) {
+		// Sin MeshTrafficPermission solo se puede revisar la configuración del Mesh.
+		findings = append(findings, notInstalledFinding(MeshTrafficPermissionType))
+	} else if err != nil {
 		return nil, fmt.Errorf("error al listar MeshTrafficPermissions: %w", err)
 	}
 
-	for _, policy := range policies.Items {
-		// --- INICIO DEL CÓDIGO CORREGIDO ---
-		// La forma correcta de acceder a un campo dentro de un elemento de una lista (slice).
-
-		// Primero, obtenemos la sección 'to' como una lista
-		toSlice, found, _ := unstructured.NestedSlice(policy.Object, "spec", "to")
-		if !found || len(toSlice) == 0 {
-			continue // Si no hay sección 'to', no podemos analizarla.
-		}
-
-		// Accedemos al primer elemento de la lista
-		firstToRule, ok := toSlice[0].(map[string]interface{})
-		if !ok {
-			continue // Si el formato no es el esperado
+	for _, policy := range filterByMesh(policies, meshName) {
+		from := policyRules(policy, "from")
+		// Sin mTLS los proxies no conocen la identidad del origen: Kuma no aplica la política.
+		if enabledBackend == "" && len(from) > 0 {
+			findings = append(findings, RuleTrafficPermissionWithoutMTLS.Finding(
+				policyRef(policy),
+				"mTLS está desactivado en el mesh: Kuma no aplica las MeshTrafficPermission y esta política no tiene efecto.",
+			))
 		}
-
-		// Ahora, buscamos la acción dentro de ese primer elemento
-		action, _, _ := unstructured.NestedString(firstToRule, "default", "action")
-		// --- FIN DEL CÓDIGO CORREGIDO ---
-
-		// Si mTLS está activo pero una política no lo fuerza, es una advertencia.
-		if enabledBackend != "" && action != "" && action != "AllowWithMTLS" {
-			findings = append(findings, MTLSFinding{
-				Level:    "WARN",
-				Message:  fmt.Sprintf("La política usa la acción '%s' en lugar de 'AllowWithMTLS', lo que podría permitir tráfico no cifrado.", action),
-				Resource: policy.GetName(),
-			})
+		for i, rule := range from {
+			action, _, _ := unstructured.NestedString(rule.Default, "action")
+			if !validTrafficActions[action] {
+				findings = append(findings, RuleTrafficPermissionInvalidAction.Finding(
+					policyRef(policy),
+					fmt.Sprintf("spec.from[%d] usa la acción '%s', que no es válida (Allow, Deny o AllowWithShadowDeny).", i, action),
+				))
+			}
 		}
 	}
 
 	return &ValidationResult{
-		Title:       "Análisis de Configuración mTLS",
+		Title:       mtlsTitle,
 		GeneratedAt: time.Now(),
 		Findings:    findings,
 	}, nil
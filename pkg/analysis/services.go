@@ -0,0 +1,188 @@
+// pkg/analysis/services.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register(NewAnalyzer("service-consistency", "Consistencia de Puertos y Etiquetas de los Servicios", CategoryDataplanes, AnalyzeServiceConsistency, "services"))
+}
+
+const serviceConsistencyTitle = "Análisis de Consistencia de Inbounds por Servicio"
+
+// serviceInbounds agrupa los inbounds de todas las réplicas de un kuma.io/service. Cada mapa
+// indexa las réplicas ("namespace/dataplane") por el valor observado.
+type serviceInbounds struct {
+	ports      map[int64][]string
+	protocols  map[string][]string // "" son los inbounds sin kuma.io/protocol
+	versions   map[string][]string // "" son los inbounds sin etiqueta version
+	namespaces map[string]bool
+	// inScope indica si alguna réplica está en el namespace de --namespace.
+	inScope bool
+}
+
+// AnalyzeServiceConsistency agrupa los inbounds de los Dataplanes por kuma.io/service y
+// busca réplicas que no coinciden entre sí: puertos distintos, kuma.io/protocol distinto o
+// ausente en parte de ellas, la etiqueta version solo en algunas y nombres de servicio que
+// se repiten en varios namespaces. Son diferencias que rompen el enrutado sin que ningún
+// proxy aparezca como no sano.
+func AnalyzeServiceConsistency(ctx context.Context, env *Env) (*ValidationResult, error) {
+	// Las réplicas de un servicio y las colisiones se ven comparando todo el mesh; --namespace
+	// solo limita los servicios de los que se informa.
+	scoped := *env
+	scoped.Namespace = ""
+	resolver, err := NewTargetResolver(ctx, &scoped)
+	if err != nil {
+		if result, ok := skipIfNotInstalled(serviceConsistencyTitle, err); ok {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	result := &ValidationResult{Title: serviceConsistencyTitle, GeneratedAt: time.Now()}
+	services := make(map[string]*serviceInbounds)
+	// shortNames son, en Kubernetes, los namespaces de cada nombre de Service.
+	shortNames := make(map[string]map[string]bool)
+	for _, proxy := range resolver.Proxies() {
+		replica := ResourceRef{Namespace: proxy.Namespace, Name: proxy.Name}.String()
+		for _, inbound := range proxy.Inbounds {
+			name := inbound.Service()
+			if name == "" {
+				continue
+			}
+			service, ok := services[name]
+			if !ok {
+				service = &serviceInbounds{
+					ports:      make(map[int64][]string),
+					protocols:  make(map[string][]string),
+					versions:   make(map[string][]string),
+					namespaces: make(map[string]bool),
+				}
+				services[name] = service
+			}
+			service.ports[inbound.Port] = append(service.ports[inbound.Port], replica)
+			service.protocols[inbound.Tags[protocolTag]] = append(service.protocols[inbound.Tags[protocolTag]], replica)
+			service.versions[inbound.Tags[versionTag]] = append(service.versions[inbound.Tags[versionTag]], replica)
+			if proxy.Namespace != "" {
+				service.namespaces[proxy.Namespace] = true
+			}
+			service.inScope = service.inScope || env.Namespace == "" || proxy.Namespace == env.Namespace
+
+			if shortName := inbound.Tags[k8sServiceNameTag]; shortName != "" {
+				namespace := inbound.Tags[k8sNamespaceTag]
+				if namespace == "" {
+					namespace = proxy.Namespace
+				}
+				if shortNames[shortName] == nil {
+					shortNames[shortName] = make(map[string]bool)
+				}
+				shortNames[shortName][namespace] = true
+			}
+		}
+	}
+
+	reported := 0
+	for _, name := range sortedKeys(services) {
+		service := services[name]
+		if !service.inScope {
+			continue
+		}
+		reported++
+		result.Findings = append(result.Findings, serviceFindings(env.Mesh, name, service)...)
+	}
+	ambiguous := make(map[string]bool)
+	for shortName, namespaces := range shortNames {
+		if len(namespaces) > 1 && (env.Namespace == "" || namespaces[env.Namespace]) {
+			ambiguous[shortName] = true
+		}
+	}
+	for _, shortName := range sortedKeys(ambiguous) {
+		namespaces := shortNames[shortName]
+		result.Findings = append(result.Findings, RuleServiceNameAmbiguous.Finding(
+			ResourceRef{Kind: "Service", Mesh: env.Mesh, Name: shortName},
+			fmt.Sprintf("Hay un Service '%s' en %d namespaces (%s): un targetRef MeshService '%s' sin namespace los selecciona todos.",
+				shortName, len(namespaces), strings.Join(sortedKeys(namespaces), ", "), shortName),
+		))
+	}
+
+	if len(result.Findings) == 0 {
+		result.Findings = append(result.Findings, RuleServicesConsistent.Finding(
+			ResourceRef{Kind: "Mesh", Mesh: env.Mesh, Name: env.Mesh},
+			fmt.Sprintf("Los inbounds de los %d servicios del mesh son coherentes entre réplicas.", reported),
+		))
+	}
+	return result, nil
+}
+
+// serviceFindings compara las réplicas de un servicio.
+func serviceFindings(mesh, name string, service *serviceInbounds) []Finding {
+	ref := ResourceRef{Kind: "Service", Mesh: mesh, Name: name}
+	var findings []Finding
+
+	if len(service.ports) > 1 {
+		findings = append(findings, RuleServicePortMismatch.Finding(ref, fmt.Sprintf(
+			"Las réplicas exponen el servicio en puertos distintos: %s.", describePorts(service.ports))))
+	}
+
+	undeclared := service.protocols[""]
+	declared := len(service.protocols)
+	if len(undeclared) > 0 {
+		declared--
+	}
+	switch {
+	case declared > 1:
+		findings = append(findings, RuleServiceProtocolMismatch.Finding(ref, fmt.Sprintf(
+			"Las réplicas declaran %s distinto: %s.", protocolTag, describeValues(service.protocols, "sin etiqueta"))))
+	case declared == 1 && len(undeclared) > 0:
+		findings = append(findings, RuleServiceProtocolPartial.Finding(ref, fmt.Sprintf(
+			"Algunas réplicas no declaran %s y se tratan como tcp: %s.", protocolTag, describeValues(service.protocols, "sin etiqueta"))))
+	case declared == 0:
+		findings = append(findings, RuleServiceProtocolUndeclared.Finding(ref, fmt.Sprintf(
+			"Ninguna réplica declara %s: Kuma trata el servicio como tcp.", protocolTag)))
+	}
+
+	if unversioned := service.versions[""]; len(unversioned) > 0 && len(service.versions) > 1 {
+		findings = append(findings, RuleServiceVersionTagPartial.Finding(ref, fmt.Sprintf(
+			"Solo algunas réplicas tienen la etiqueta %s: %s.", versionTag, describeValues(service.versions, "sin etiqueta"))))
+	}
+
+	if len(service.namespaces) > 1 {
+		findings = append(findings, RuleServiceNameCollision.Finding(ref, fmt.Sprintf(
+			"Dataplanes de %d namespaces (%s) declaran el mismo kuma.io/service: el mesh los trata como un único servicio y reparte el tráfico entre todos.",
+			len(service.namespaces), strings.Join(sortedKeys(service.namespaces), ", "))))
+	}
+	return findings
+}
+
+// describePorts resume las réplicas de cada puerto ("8080 (demo/web-1), 9090 (demo/web-2)").
+func describePorts(ports map[int64][]string) string {
+	sorted := make([]int64, 0, len(ports))
+	for port := range ports {
+		sorted = append(sorted, port)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	parts := make([]string, 0, len(sorted))
+	for _, port := range sorted {
+		parts = append(parts, fmt.Sprintf("%d (%s)", port, listDataplanes(ports[port])))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeValues resume las réplicas de cada valor de una etiqueta; las que no la tienen
+// se nombran con missing.
+func describeValues(values map[string][]string, missing string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range sortedKeys(values) {
+		label := value
+		if label == "" {
+			label = missing
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", label, listDataplanes(values[value])))
+	}
+	return strings.Join(parts, ", ")
+}
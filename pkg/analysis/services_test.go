@@ -0,0 +1,140 @@
+// pkg/analysis/services_test.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// serviceMesh precede a los Dataplanes de cada caso, que empiezan con un separador.
+const serviceMesh = `
+apiVersion: kuma.io/v1alpha1
+kind: Mesh
+metadata: {name: default}
+`
+
+// serviceDataplane devuelve un Dataplane del mesh default con un inbound en port y las
+// etiquetas indicadas (en sintaxis de flujo YAML, sin llaves).
+func serviceDataplane(name, namespace string, port int, tags string) string {
+	return fmt.Sprintf(`
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: %s, namespace: %s, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: %d, tags: {%s}}]}}
+`, name, namespace, port, tags)
+}
+
+func TestAnalyzeServiceConsistency(t *testing.T) {
+	const web = "kuma.io/service: web, kuma.io/protocol: http"
+	tests := []struct {
+		name       string
+		dataplanes string
+		namespace  string
+		findings   []string
+	}{
+		{
+			name:       "réplicas coherentes",
+			dataplanes: serviceDataplane("web-1", "demo", 8080, web) + serviceDataplane("web-2", "demo", 8080, web),
+			findings:   []string{RuleServicesConsistent.ID},
+		},
+		{
+			name:       "puertos distintos",
+			dataplanes: serviceDataplane("web-1", "demo", 8080, web) + serviceDataplane("web-2", "demo", 9090, web),
+			findings:   []string{RuleServicePortMismatch.ID},
+		},
+		{
+			name: "protocolos distintos",
+			dataplanes: serviceDataplane("web-1", "demo", 8080, web) +
+				serviceDataplane("web-2", "demo", 8080, "kuma.io/service: web, kuma.io/protocol: tcp"),
+			findings: []string{RuleServiceProtocolMismatch.ID},
+		},
+		{
+			name:       "protocolo solo en algunas réplicas",
+			dataplanes: serviceDataplane("web-1", "demo", 8080, web) + serviceDataplane("web-2", "demo", 8080, "kuma.io/service: web"),
+			findings:   []string{RuleServiceProtocolPartial.ID},
+		},
+		{
+			name:       "sin protocolo en ninguna réplica",
+			dataplanes: serviceDataplane("web-1", "demo", 8080, "kuma.io/service: web"),
+			findings:   []string{RuleServiceProtocolUndeclared.ID},
+		},
+		{
+			name: "version solo en algunas réplicas",
+			dataplanes: serviceDataplane("web-1", "demo", 8080, web+", version: v1") +
+				serviceDataplane("web-2", "demo", 8080, web),
+			findings: []string{RuleServiceVersionTagPartial.ID},
+		},
+		{
+			name:       "el mismo kuma.io/service en dos namespaces",
+			dataplanes: serviceDataplane("web-1", "demo", 8080, web) + serviceDataplane("web-1", "staging", 8080, web),
+			findings:   []string{RuleServiceNameCollision.ID},
+		},
+		{
+			name: "un Service con el mismo nombre en dos namespaces",
+			dataplanes: serviceDataplane("web-1", "demo", 8080, "kuma.io/service: web_demo_svc_8080, kuma.io/protocol: http, k8s.kuma.io/service-name: web, k8s.kuma.io/namespace: demo") +
+				serviceDataplane("web-1", "staging", 8080, "kuma.io/service: web_staging_svc_8080, kuma.io/protocol: http, k8s.kuma.io/service-name: web, k8s.kuma.io/namespace: staging"),
+			findings: []string{RuleServiceNameAmbiguous.ID},
+		},
+		{
+			// Las réplicas de otros namespaces cuentan para comparar, pero solo se informa de
+			// los servicios con alguna réplica en el namespace solicitado.
+			name: "--namespace limita los servicios de los que se informa",
+			dataplanes: serviceDataplane("web-1", "demo", 8080, web) + serviceDataplane("web-2", "demo", 9090, web) +
+				serviceDataplane("api-1", "staging", 8080, "kuma.io/service: api"),
+			namespace: "demo",
+			findings:  []string{RuleServicePortMismatch.ID},
+		},
+		{
+			name:       "servicio fuera del namespace solicitado",
+			dataplanes: serviceDataplane("api-1", "staging", 8080, "kuma.io/service: api"),
+			namespace:  "demo",
+			findings:   []string{RuleServicesConsistent.ID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &Env{Source: newFakeSource(t, serviceMesh+tt.dataplanes), Mesh: "default", Namespace: tt.namespace}
+			result, err := AnalyzeServiceConsistency(context.Background(), env)
+			if err != nil {
+				t.Fatalf("AnalyzeServiceConsistency: %v", err)
+			}
+			var ids []string
+			for _, finding := range result.Findings {
+				ids = append(ids, finding.RuleID)
+			}
+			if !reflect.DeepEqual(ids, tt.findings) {
+				t.Errorf("hallazgos = %v, se esperaba %v", ids, tt.findings)
+			}
+		})
+	}
+}
+
+func TestDescribeValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string][]string
+		want   string
+	}{
+		{name: "sin valores", values: map[string][]string{}, want: ""},
+		{
+			name:   "ordenados por valor",
+			values: map[string][]string{"tcp": {"demo/web-2"}, "http": {"demo/web-1"}},
+			want:   "http (demo/web-1), tcp (demo/web-2)",
+		},
+		{
+			name:   "las réplicas sin etiqueta se nombran con missing",
+			values: map[string][]string{"": {"demo/web-2"}, "v1": {"demo/web-1"}},
+			want:   "sin etiqueta (demo/web-2), v1 (demo/web-1)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeValues(tt.values, "sin etiqueta"); got != tt.want {
+				t.Errorf("describeValues = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// pkg/analysis/snapshot.go
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Snapshot es un Source que consulta cada tipo de recurso una sola vez y comparte el
+// resultado entre todos los analizadores de una ejecución. Un reporte completo lista los
+// Dataplanes desde varios analizadores; sin la instantánea cada uno repetiría la consulta
+// completa contra el API server.
+//
+// Los resultados son una vista inmutable: cada llamada devuelve copias, de modo que un
+// analizador no puede alterar lo que ven los demás.
+//
+// Si el origen implementa Discoverer, los tipos del grupo kuma.io se resuelven a la versión
+// y el nombre de recurso que sirve realmente el servidor, y los tipos no instalados devuelven
+// un NotInstalledError en lugar de un NotFound genérico.
+type Snapshot struct {
+	source Source
+
+	mu          sync.Mutex
+	entries     map[snapshotKey]*snapshotEntry
+	discoveries map[string]*discoveryEntry
+
+	versionOnce sync.Once
+	version     string
+	versionErr  error
+}
+
+// discoveryEntry usa un mutex en lugar de sync.Once porque un intento interrumpido por el
+// contexto no se cachea y se repite en la siguiente llamada.
+type discoveryEntry struct {
+	mu        sync.Mutex
+	done      bool
+	discovery *APIDiscovery
+	err       error
+}
+
+type snapshotKey struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+type snapshotEntry struct {
+	once  sync.Once
+	items []unstructured.Unstructured
+	err   error
+}
+
+// NewSnapshot envuelve source en una instantánea vacía; los recursos se cargan la primera vez
+// que algún analizador los pide.
+func NewSnapshot(source Source) *Snapshot {
+	return &Snapshot{
+		source:      source,
+		entries:     make(map[snapshotKey]*snapshotEntry),
+		discoveries: make(map[string]*discoveryEntry),
+	}
+}
+
+// Discover consulta la API de discovery del origen una sola vez por grupo. Devuelve
+// ErrDiscoveryUnsupported si el origen no la ofrece. Los errores también se cachean, salvo
+// los de una consulta que se interrumpió porque ctx se canceló.
+func (s *Snapshot) Discover(ctx context.Context, group string) (*APIDiscovery, error) {
+	discoverer, ok := s.source.(Discoverer)
+	if !ok {
+		return nil, ErrDiscoveryUnsupported
+	}
+
+	s.mu.Lock()
+	entry, ok := s.discoveries[group]
+	if !ok {
+		entry = &discoveryEntry{}
+		s.discoveries[group] = entry
+	}
+	s.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if !entry.done {
+		discovery, err := discoverer.Discover(ctx, group)
+		if err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		entry.discovery, entry.err, entry.done = discovery, err, true
+	}
+	return entry.discovery, entry.err
+}
+
+// ControlPlaneVersion consulta la versión del control plane una sola vez. Devuelve
+// ErrControlPlaneVersionUnsupported si el origen no la ofrece.
+func (s *Snapshot) ControlPlaneVersion(ctx context.Context) (string, error) {
+	versioner, ok := s.source.(ControlPlaneVersioner)
+	if !ok {
+		return "", ErrControlPlaneVersionUnsupported
+	}
+	s.versionOnce.Do(func() {
+		s.version, s.versionErr = versioner.ControlPlaneVersion(ctx)
+	})
+	return s.version, s.versionErr
+}
+
+// resolve traduce un tipo del catálogo al que sirve el servidor según discovery. Si el origen
+// no ofrece discovery se usa el tipo del catálogo tal cual; cualquier otro fallo se devuelve
+// para que los analizadores lo informen en lugar de listar a ciegas.
+func (s *Snapshot) resolve(ctx context.Context, rt ResourceType) (ResourceType, error) {
+	if rt.GVR.Group != kumaGroup {
+		return rt, nil
+	}
+	discovery, err := s.Discover(ctx, kumaGroup)
+	if errors.Is(err, ErrDiscoveryUnsupported) || apierrors.IsNotFound(err) {
+		return rt, nil
+	}
+	if err != nil {
+		return rt, fmt.Errorf("error al consultar los CRDs de %s instalados: %w", kumaGroup, err)
+	}
+	resolved, ok := discovery.Resolve(rt)
+	if !ok {
+		return rt, &NotInstalledError{Type: rt}
+	}
+	return resolved, nil
+}
+
+// List devuelve los recursos del tipo indicado, consultando el origen solo la primera vez.
+func (s *Snapshot) List(ctx context.Context, rt ResourceType, namespace string) ([]unstructured.Unstructured, error) {
+	items, err := s.load(ctx, rt, namespace)
+	if err != nil {
+		return nil, err
+	}
+	copies := make([]unstructured.Unstructured, len(items))
+	for i := range items {
+		items[i].DeepCopyInto(&copies[i])
+	}
+	return copies, nil
+}
+
+// Get busca el recurso en la lista cacheada de su tipo en lugar de hacer otra petición.
+func (s *Snapshot) Get(ctx context.Context, rt ResourceType, namespace, name string) (*unstructured.Unstructured, error) {
+	items, err := s.load(ctx, rt, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].GetName() == name {
+			return items[i].DeepCopy(), nil
+		}
+	}
+	return nil, apierrors.NewNotFound(rt.GVR.GroupResource(), name)
+}
+
+// load consulta el origen una única vez por tipo y namespace, también cuando varios
+// analizadores lo piden a la vez. Los errores se cachean igual que los resultados: un CRD
+// no instalado no se vuelve a consultar en cada analizador.
+func (s *Snapshot) load(ctx context.Context, rt ResourceType, namespace string) ([]unstructured.Unstructured, error) {
+	rt, err := s.resolve(ctx, rt)
+	if err != nil {
+		return nil, err
+	}
+	if !rt.Namespaced {
+		namespace = ""
+	}
+	key := snapshotKey{gvr: rt.GVR, namespace: namespace}
+
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &snapshotEntry{}
+		s.entries[key] = entry
+	}
+	s.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.items, entry.err = s.source.List(ctx, rt, namespace)
+		if entry.err != nil {
+			entry.err = asNotInstalled(rt, entry.err)
+			return
+		}
+		// El orden de los orígenes en memoria no es estable; ordenamos igual que el API server
+		// (namespace y nombre) para que los reportes sean reproducibles.
+		sort.SliceStable(entry.items, func(i, j int) bool {
+			a, b := entry.items[i], entry.items[j]
+			if a.GetNamespace() != b.GetNamespace() {
+				return a.GetNamespace() < b.GetNamespace()
+			}
+			return a.GetName() < b.GetName()
+		})
+	})
+	return entry.items, entry.err
+}
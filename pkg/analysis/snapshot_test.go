@@ -0,0 +1,104 @@
+// pkg/analysis/snapshot_test.go
+package analysis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// discoveringSource añade a fakeSource un Discover que devuelve, en cada llamada, el siguiente
+// error de errs (nil cuando se agotan) y cuenta las llamadas.
+type discoveringSource struct {
+	*fakeSource
+	errs  []error
+	calls int
+}
+
+func (s *discoveringSource) Discover(ctx context.Context, group string) (*APIDiscovery, error) {
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &APIDiscovery{
+		Group:            group,
+		PreferredVersion: "v1alpha1",
+		Resources:        map[string]map[string]DiscoveredResource{"v1alpha1": {"Dataplane": {Name: "dataplanes", Namespaced: true}}},
+	}, nil
+}
+
+const snapshotDataplane = `
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: web-1, namespace: demo, labels: {kuma.io/mesh: default}}
+`
+
+func TestSnapshotDiscovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		errs    []error
+		rt      ResourceType
+		wantErr string
+		missing bool
+	}{
+		{name: "discovery sin errores", rt: DataplaneType},
+		{name: "tipo no servido", rt: MeshRetryType, missing: true},
+		{name: "origen sin discovery", errs: []error{ErrDiscoveryUnsupported}, rt: DataplaneType},
+		{name: "fallo de discovery", errs: []error{errors.New("the server is currently unable to handle the request")}, rt: DataplaneType, wantErr: "unable to handle the request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := NewSnapshot(&discoveringSource{fakeSource: newFakeSource(t, snapshotDataplane), errs: tt.errs})
+			items, err := snapshot.List(context.Background(), tt.rt, "")
+			switch {
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("List = %v, se esperaba el error de discovery %q", err, tt.wantErr)
+				}
+			case tt.missing:
+				if !IsNotInstalled(err) {
+					t.Errorf("List = %v, se esperaba NotInstalledError", err)
+				}
+			case err != nil || len(items) != 1:
+				t.Errorf("List = %d elementos (%v), se esperaba el Dataplane", len(items), err)
+			}
+		})
+	}
+}
+
+func TestSnapshotDiscoveryCache(t *testing.T) {
+	failure := errors.New("the server is currently unable to handle the request")
+	source := &discoveringSource{fakeSource: newFakeSource(t, snapshotDataplane), errs: []error{failure}}
+	snapshot := NewSnapshot(source)
+	for i := 0; i < 2; i++ {
+		if _, err := snapshot.Discover(context.Background(), kumaGroup); !errors.Is(err, failure) {
+			t.Errorf("Discover (%d) = %v, se esperaba el error cacheado", i+1, err)
+		}
+	}
+	if source.calls != 1 {
+		t.Errorf("se consultó discovery %d veces, se esperaba 1", source.calls)
+	}
+}
+
+func TestSnapshotDiscoveryCancelledIsNotCached(t *testing.T) {
+	source := &discoveringSource{fakeSource: newFakeSource(t, snapshotDataplane), errs: []error{context.Canceled}}
+	snapshot := NewSnapshot(source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := snapshot.Discover(ctx, kumaGroup); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Discover con el contexto cancelado = %v, se esperaba context.Canceled", err)
+	}
+	discovery, err := snapshot.Discover(context.Background(), kumaGroup)
+	if err != nil || !discovery.Installed() {
+		t.Errorf("Discover tras la cancelación = %v, se esperaba repetir la consulta", err)
+	}
+	if source.calls != 2 {
+		t.Errorf("se consultó discovery %d veces, se esperaban 2", source.calls)
+	}
+}
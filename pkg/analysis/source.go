@@ -0,0 +1,21 @@
+// pkg/analysis/source.go
+package analysis
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Source abstrae el origen de los recursos de Kuma que leen los analizadores: la API de
+// Kubernetes (CRDs), manifiestos exportados o la API REST del control plane en despliegues
+// Universal. Todas las implementaciones devuelven los recursos con la forma de los CRDs de
+// Kubernetes (metadata, spec y la etiqueta kuma.io/mesh), para que los analizadores no
+// dependan del origen.
+type Source interface {
+	// List devuelve todos los recursos del tipo indicado. Un namespace vacío significa todos;
+	// los orígenes sin namespaces (Universal) lo ignoran.
+	List(ctx context.Context, rt ResourceType, namespace string) ([]unstructured.Unstructured, error)
+	// Get devuelve un recurso por nombre. Para los tipos sin namespace, namespace se ignora.
+	Get(ctx context.Context, rt ResourceType, namespace, name string) (*unstructured.Unstructured, error)
+}
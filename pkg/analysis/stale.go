@@ -0,0 +1,102 @@
+// pkg/analysis/stale.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// dataplaneStaleAfter es el tiempo que un Dataplane puede llevar desconectado antes de
+// considerarlo obsoleto. En Universal un proxy que desaparece sin darse de baja deja
+// su Dataplane registrado indefinidamente.
+const dataplaneStaleAfter = time.Hour
+
+// staleDataplanes devuelve, indexados por dataplaneKey, los Dataplanes obsoletos con el motivo
+// y el comando para limpiarlos:
+//   - en Kubernetes, los que sobreviven a su pod: no existe un pod con ese nombre o, si el
+//     Dataplane tiene ownerReference, el pod con ese nombre es otro (p. ej. una réplica de un
+//     StatefulSet que se ha recreado);
+//   - en cualquier caso, los que llevan más de dataplaneStaleAfter desconectados según su
+//     DataplaneInsight.
+//
+// insights puede ser nil. Los pods que no se pueden leer por otro motivo que no existir el
+// tipo se devuelven como diagnósticos.
+func staleDataplanes(ctx context.Context, env *Env, dataplanes []unstructured.Unstructured, insights map[string]*DataplaneInsightStatus, now time.Time) (map[string]string, []string) {
+	stale := make(map[string]string)
+	var diagnostics []string
+	pods := make(map[string][]unstructured.Unstructured)
+	readable := make(map[string]bool)
+	listPods := func(namespace string) ([]unstructured.Unstructured, bool) {
+		if namespace == "" {
+			return nil, false
+		}
+		if _, listed := readable[namespace]; !listed {
+			items, err := env.Source.List(ctx, PodType, namespace)
+			if err != nil && !IsNotInstalled(err) {
+				diagnostics = append(diagnostics, fmt.Sprintf("namespace %s: %s", namespace, unavailableDiagnostic(PodType, err)))
+			}
+			pods[namespace], readable[namespace] = items, err == nil
+		}
+		return pods[namespace], readable[namespace]
+	}
+
+	for _, dp := range dataplanes {
+		if nsPods, ok := listPods(dp.GetNamespace()); ok {
+			if reason := missingPod(dp, nsPods); reason != "" {
+				stale[dataplaneKey(dp)] = fmt.Sprintf("%s. Limpieza: %s", reason, cleanupCommand(dp))
+				continue
+			}
+		}
+
+		insight := insights[dataplaneKey(dp)]
+		if insight == nil || insight.Connected || insight.DisconnectTime == nil {
+			continue
+		}
+		if since := now.Sub(*insight.DisconnectTime); since > dataplaneStaleAfter {
+			stale[dataplaneKey(dp)] = fmt.Sprintf("Sin conexión con el control plane desde hace %s (%s). Limpieza: %s",
+				since.Round(time.Minute), formatTime(insight.DisconnectTime), cleanupCommand(dp))
+		}
+	}
+	return stale, diagnostics
+}
+
+// missingPod indica por qué el pod de un Dataplane ya no existe entre pods, o devuelve "" si
+// existe. Con ownerReference se compara también el UID: un pod recreado con el mismo nombre
+// no es el del Dataplane.
+func missingPod(dp unstructured.Unstructured, pods []unstructured.Unstructured) string {
+	name, uid := dataplanePod(dp)
+	for _, pod := range pods {
+		if pod.GetName() != name {
+			continue
+		}
+		if uid != "" && pod.GetUID() != uid {
+			return fmt.Sprintf("El pod %s/%s se ha recreado (UID %s, el Dataplane es del UID %s)", dp.GetNamespace(), name, pod.GetUID(), uid)
+		}
+		return ""
+	}
+	return fmt.Sprintf("El pod %s/%s ya no existe", dp.GetNamespace(), name)
+}
+
+// cleanupCommand devuelve el comando para borrar un Dataplane obsoleto.
+func cleanupCommand(dp unstructured.Unstructured) string {
+	if dp.GetNamespace() != "" {
+		return fmt.Sprintf("kubectl delete dataplane %s -n %s", dp.GetName(), dp.GetNamespace())
+	}
+	return fmt.Sprintf("kumactl delete dataplane %s --mesh %s", dp.GetName(), meshOf(dp))
+}
+
+// dataplanePod devuelve el nombre y el UID del pod de un Dataplane: los de su ownerReference
+// o, si no la tiene, el nombre del propio Dataplane, que Kuma crea con el mismo nombre que el
+// pod, y un UID vacío.
+func dataplanePod(dp unstructured.Unstructured) (string, types.UID) {
+	for _, owner := range dp.GetOwnerReferences() {
+		if owner.Kind == "Pod" {
+			return owner.Name, owner.UID
+		}
+	}
+	return dp.GetName(), ""
+}
@@ -0,0 +1,96 @@
+// pkg/analysis/stale_test.go
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stalePods son los pods del namespace demo: web-0 es una réplica de StatefulSet (UID uid-2,
+// recreada tras la uid-1) y api-1 un pod normal.
+const stalePods = `
+apiVersion: v1
+kind: Pod
+metadata: {name: web-0, namespace: demo, uid: uid-2}
+---
+apiVersion: v1
+kind: Pod
+metadata: {name: api-1, namespace: demo, uid: uid-3}
+`
+
+func TestStaleDataplanes(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	disconnected := func(ago time.Duration) *DataplaneInsightStatus {
+		at := now.Add(-ago)
+		return &DataplaneInsightStatus{DisconnectTime: &at}
+	}
+
+	tests := []struct {
+		name      string
+		dataplane string
+		insight   *DataplaneInsightStatus
+		reason    string // fragmento del motivo, vacío si no es obsoleto
+	}{
+		{
+			name:      "pod con el mismo UID",
+			dataplane: `{name: web-0, namespace: demo, ownerReferences: [{apiVersion: v1, kind: Pod, name: web-0, uid: uid-2}]}`,
+		},
+		{
+			name:      "pod recreado con otro UID",
+			dataplane: `{name: web-0, namespace: demo, ownerReferences: [{apiVersion: v1, kind: Pod, name: web-0, uid: uid-1}]}`,
+			reason:    "se ha recreado",
+		},
+		{
+			name:      "pod inexistente",
+			dataplane: `{name: db-0, namespace: demo, ownerReferences: [{apiVersion: v1, kind: Pod, name: db-0, uid: uid-4}]}`,
+			reason:    "El pod demo/db-0 ya no existe",
+		},
+		{
+			name:      "sin ownerReference se busca el pod por el nombre del Dataplane",
+			dataplane: `{name: api-1, namespace: demo}`,
+		},
+		{
+			name:      "pod existente pero desconectado desde hace más de una hora",
+			dataplane: `{name: api-1, namespace: demo}`,
+			insight:   disconnected(2 * time.Hour),
+			reason:    "Sin conexión con el control plane desde hace 2h0m0s",
+		},
+		{
+			name:      "Universal desconectado desde hace más de una hora",
+			dataplane: `{name: vm-1}`,
+			insight:   disconnected(2 * time.Hour),
+			reason:    "kumactl delete dataplane vm-1 --mesh default",
+		},
+		{
+			name:      "Universal desconectado desde hace poco",
+			dataplane: `{name: vm-1}`,
+			insight:   disconnected(10 * time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newFakeSource(t, stalePods+"---\napiVersion: kuma.io/v1alpha1\nkind: Dataplane\nmetadata: "+tt.dataplane)
+			env := &Env{Source: source, Mesh: "default"}
+			dataplanes, _ := source.List(context.Background(), DataplaneType, "")
+			dataplanes[0].SetLabels(map[string]string{"kuma.io/mesh": "default"})
+			insights := map[string]*DataplaneInsightStatus{}
+			if tt.insight != nil {
+				insights[dataplaneKey(dataplanes[0])] = tt.insight
+			}
+
+			stale, diagnostics := staleDataplanes(context.Background(), env, dataplanes, insights, now)
+			if len(diagnostics) > 0 {
+				t.Errorf("diagnósticos inesperados: %v", diagnostics)
+			}
+			reason, found := stale[dataplaneKey(dataplanes[0])]
+			switch {
+			case tt.reason == "" && found:
+				t.Errorf("el Dataplane no debería ser obsoleto: %s", reason)
+			case tt.reason != "" && !strings.Contains(reason, tt.reason):
+				t.Errorf("motivo = %q, se esperaba que incluyera %q", reason, tt.reason)
+			}
+		})
+	}
+}
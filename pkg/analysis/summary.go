@@ -5,33 +5,57 @@ import (
 	"context"
 	"fmt"
 	"time"
-
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
 )
 
-// AnalyzeSummary ejecuta un análisis de alto nivel de todo el mesh.
-func AnalyzeSummary(client dynamic.Interface) (*ValidationResult, error) {
+func init() {
+	Register(NewAnalyzer("summary", "Resumen General de Salud", CategoryGeneral, AnalyzeSummary))
+}
+
+const summaryTitle = "Resumen General de Salud del Mesh"
+
+// AnalyzeSummary ejecuta un análisis de alto nivel del mesh. El total de meshes se refiere
+// siempre a todo el clúster; el resto de cifras, al mesh analizado.
+func AnalyzeSummary(ctx context.Context, env *Env) (*ValidationResult, error) {
 	summary := SummaryStatus{}
 
 	// 1. Contar Meshes
-	meshGVR := schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: "meshes"}
-	meshes, err := client.Resource(meshGVR).List(context.TODO(), v1.ListOptions{})
+	meshes, err := env.Source.List(ctx, MeshType, "")
 	if err != nil {
+		if result, ok := skipIfNotInstalled(summaryTitle, err); ok {
+			return result, nil
+		}
 		return nil, fmt.Errorf("error al listar Meshes: %w", err)
 	}
-	summary.TotalMeshes = len(meshes.Items)
+	summary.TotalMeshes = len(meshes)
 
 	// 2. Contar y clasificar Dataplanes
-	dataplaneGVR := schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: "dataplanes"}
-	dataplanes, err := client.Resource(dataplaneGVR).List(context.TODO(), v1.ListOptions{})
+	dataplanes, err := env.Source.List(ctx, DataplaneType, env.Namespace)
 	if err != nil {
+		if result, ok := skipIfNotInstalled(summaryTitle, err); ok {
+			return result, nil
+		}
 		return nil, fmt.Errorf("error al listar Dataplanes: %w", err)
 	}
-	summary.TotalDataplanes = len(dataplanes.Items)
+	meshDataplanes := filterByMesh(dataplanes, env.Mesh)
+	summary.TotalDataplanes = len(meshDataplanes)
+
+	// Los Dataplanes obsoletos no cuentan como Offline: su proxy ya no existe. Sin insights solo
+	// se detectan los que han sobrevivido a su pod; el motivo queda en los diagnósticos.
+	var diagnostics []string
+	var insights map[string]*DataplaneInsightStatus
+	if len(meshDataplanes) > 0 {
+		if insights, err = dataplaneInsights(ctx, env); err != nil {
+			diagnostics = append(diagnostics, err.Error())
+		}
+	}
+	stale, staleDiagnostics := staleDataplanes(ctx, env, meshDataplanes, insights, time.Now())
+	diagnostics = append(diagnostics, staleDiagnostics...)
 
-	for _, dp := range dataplanes.Items {
+	for _, dp := range meshDataplanes {
+		if _, ok := stale[dataplaneKey(dp)]; ok {
+			summary.StaleDataplanes++
+			continue
+		}
 		status, _ := getDataplaneStatusFromInbounds(dp)
 		switch status.Overall {
 		case "Online":
@@ -45,20 +69,20 @@ func AnalyzeSummary(client dynamic.Interface) (*ValidationResult, error) {
 		}
 	}
 
+	result := &ValidationResult{
+		Title:       summaryTitle,
+		GeneratedAt: time.Now(),
+		Summary:     &summary, // El resumen no genera hallazgos, solo cifras
+		Diagnostics: diagnostics,
+	}
+
 	// 3. Contar Políticas (ejemplo con MeshTrafficPermission)
-	mtpGVR := schema.GroupVersionResource{Group: "kuma.io", Version: "v1alpha1", Resource: "meshtrafficpermissions"}
-	policies, err := client.Resource(mtpGVR).List(context.TODO(), v1.ListOptions{})
+	policies, err := env.Source.List(ctx, MeshTrafficPermissionType, "")
 	if err != nil {
 		// No hacemos que falle todo si solo falla un tipo de política
-		fmt.Printf("Advertencia: no se pudieron listar MeshTrafficPermissions: %v\n", err)
+		result.Diagnostics = append(result.Diagnostics, fmt.Sprintf("no se pudieron listar MeshTrafficPermissions: %v", err))
 	} else {
-		summary.TotalPolicies = len(policies.Items)
-	}
-
-	result := &ValidationResult{
-		Title:       "Resumen General de Salud del Mesh",
-		GeneratedAt: time.Now(),
-		Findings:    []interface{}{summary}, // El único hallazgo es el propio resumen
+		summary.TotalPolicies = len(filterByMesh(policies, env.Mesh))
 	}
 
 	return result, nil
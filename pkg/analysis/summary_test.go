@@ -0,0 +1,33 @@
+// pkg/analysis/summary_test.go
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeSummaryDiagnostics(t *testing.T) {
+	// web-1 ha sobrevivido a su pod; no hay DataplaneInsights que consultar.
+	env := &Env{Source: newFakeSource(t, `
+apiVersion: kuma.io/v1alpha1
+kind: Mesh
+metadata: {name: default}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: web-1, namespace: demo, labels: {kuma.io/mesh: default}}
+spec: {networking: {inbound: [{port: 80, tags: {kuma.io/service: web}}]}}
+`), Mesh: "default"}
+
+	result, err := AnalyzeSummary(context.Background(), env)
+	if err != nil {
+		t.Fatalf("AnalyzeSummary: %v", err)
+	}
+	if result.Summary.StaleDataplanes != 1 || result.Summary.TotalDataplanes != 1 {
+		t.Errorf("Dataplanes obsoletos = %d de %d, se esperaba 1 de 1", result.Summary.StaleDataplanes, result.Summary.TotalDataplanes)
+	}
+	if len(result.Diagnostics) != 1 || !strings.Contains(result.Diagnostics[0], "no hay DataplaneInsights") {
+		t.Errorf("diagnósticos = %v, se esperaba el aviso de que faltan los DataplaneInsights", result.Diagnostics)
+	}
+}
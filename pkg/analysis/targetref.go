@@ -0,0 +1,482 @@
+// pkg/analysis/targetref.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Tipos de targetRef soportados por las políticas de Kuma.
+const (
+	TargetMesh              = "Mesh"
+	TargetMeshSubset        = "MeshSubset"
+	TargetMeshService       = "MeshService"
+	TargetMeshServiceSubset = "MeshServiceSubset"
+	TargetMeshGateway       = "MeshGateway"
+)
+
+// Etiquetas de los inbounds que identifican a un servicio.
+const (
+	serviceTag        = "kuma.io/service"
+	k8sServiceNameTag = "k8s.kuma.io/service-name"
+	k8sNamespaceTag   = "k8s.kuma.io/namespace"
+	k8sServicePortTag = "k8s.kuma.io/service-port"
+	protocolTag       = "kuma.io/protocol"
+	versionTag        = "version"
+)
+
+// TargetRef es un selector de Kuma ('spec.targetRef', 'spec.to[].targetRef' o
+// 'spec.from[].targetRef').
+type TargetRef struct {
+	Kind      string            `json:"kind"`
+	Name      string            `json:"name,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	// ProxyTypes limita los kinds Mesh y MeshSubset a sidecars ("Sidecar") o gateways ("Gateway").
+	ProxyTypes []string `json:"proxyTypes,omitempty"`
+	// policyNamespace es el namespace de la política que declara un MeshService o
+	// MeshServiceSubset sin namespace: el que se usa para resolver el nombre del Service.
+	policyNamespace string
+}
+
+// String devuelve una representación compacta del targetRef (p. ej. "MeshService/backend"
+// o "MeshSubset[team=front]"). Dos targetRef equivalentes producen siempre la misma cadena.
+func (r TargetRef) String() string {
+	s := r.Kind
+	if r.Namespace != "" {
+		s += "/" + r.Namespace
+	}
+	if r.Name != "" {
+		s += "/" + r.Name
+	}
+	if len(r.Tags) > 0 {
+		tags := make([]string, 0, len(r.Tags))
+		for key, value := range r.Tags {
+			tags = append(tags, key+"="+value)
+		}
+		sort.Strings(tags)
+		s += "[" + strings.Join(tags, ",") + "]"
+	}
+	return s
+}
+
+// key identifica el selector completo, incluidos los proxyTypes y el namespace de la
+// política, para comparar targetRefs.
+func (r TargetRef) key() string {
+	key := r.String()
+	if r.policyNamespace != "" {
+		key += "@" + r.policyNamespace
+	}
+	if len(r.ProxyTypes) > 0 {
+		key += "{" + strings.Join(r.ProxyTypes, ",") + "}"
+	}
+	return key
+}
+
+// namespace devuelve el namespace en el que se busca el Service de un MeshService por su
+// nombre corto: el del targetRef o, si no lo indica, el de la política.
+func (r TargetRef) namespace() string {
+	if r.Namespace != "" {
+		return r.Namespace
+	}
+	return r.policyNamespace
+}
+
+// parseTargetRef lee un targetRef de un mapa. Un targetRef ausente equivale a 'kind: Mesh',
+// igual que en Kuma.
+func parseTargetRef(obj map[string]interface{}, fields ...string) TargetRef {
+	raw, found, _ := unstructured.NestedMap(obj, fields...)
+	if !found {
+		return TargetRef{Kind: TargetMesh}
+	}
+	ref := TargetRef{}
+	ref.Kind, _, _ = unstructured.NestedString(raw, "kind")
+	ref.Name, _, _ = unstructured.NestedString(raw, "name")
+	ref.Namespace, _, _ = unstructured.NestedString(raw, "namespace")
+	ref.Tags, _, _ = unstructured.NestedStringMap(raw, "tags")
+	ref.ProxyTypes, _, _ = unstructured.NestedStringSlice(raw, "proxyTypes")
+	if ref.Kind == "" {
+		ref.Kind = TargetMesh
+	}
+	return ref
+}
+
+// policyTargetRef lee el 'spec.targetRef' de una política.
+func policyTargetRef(policy unstructured.Unstructured) TargetRef {
+	return inPolicyNamespace(parseTargetRef(policy.Object, "spec", "targetRef"), policy)
+}
+
+// inPolicyNamespace completa un targetRef MeshService o MeshServiceSubset sin namespace con el
+// de la política, igual que Kuma, que busca el Service por su nombre en ese namespace.
+func inPolicyNamespace(ref TargetRef, policy unstructured.Unstructured) TargetRef {
+	if (ref.Kind == TargetMeshService || ref.Kind == TargetMeshServiceSubset) && ref.Namespace == "" {
+		ref.policyNamespace = policy.GetNamespace()
+	}
+	return ref
+}
+
+// PolicyRule es una entrada de 'spec.to' o 'spec.from' de una política.
+type PolicyRule struct {
+	TargetRef TargetRef
+	Default   map[string]interface{}
+}
+
+// policyRules devuelve las entradas de 'spec.<section>' ("to" o "from") de una política.
+// Las rutas (MeshHTTPRoute, MeshTCPRoute) no tienen 'default': su configuración son las
+// 'rules' de cada entrada.
+func policyRules(policy unstructured.Unstructured, section string) []PolicyRule {
+	items, _, _ := unstructured.NestedSlice(policy.Object, "spec", section)
+	var rules []PolicyRule
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conf, found, _ := unstructured.NestedMap(itemMap, "default")
+		if routes, ok := itemMap["rules"]; !found && ok {
+			conf = map[string]interface{}{"rules": routes}
+		}
+		ref := inPolicyNamespace(parseTargetRef(itemMap, "targetRef"), policy)
+		rules = append(rules, PolicyRule{TargetRef: ref, Default: conf})
+	}
+	return rules
+}
+
+// --- Modelo de Dataplane ---
+
+// Proxy es la vista parseada de un Dataplane que usan los resolvers de targetRef.
+type Proxy struct {
+	Name      string
+	Namespace string
+	Mesh      string
+	Inbounds  []Inbound
+	// GatewayTags son las etiquetas del gateway si el Dataplane es un gateway (builtin o delegated).
+	GatewayTags map[string]string
+}
+
+// Inbound es un inbound de un Dataplane.
+type Inbound struct {
+	Port int64
+	Tags map[string]string
+}
+
+// Service devuelve el nombre del servicio (kuma.io/service) del inbound.
+func (i Inbound) Service() string {
+	return i.Tags[serviceTag]
+}
+
+// IsGateway indica si el Dataplane es un gateway.
+func (p Proxy) IsGateway() bool {
+	return p.GatewayTags != nil
+}
+
+// Services devuelve los servicios que expone el Dataplane, sin repetir y en orden.
+func (p Proxy) Services() []string {
+	services := make(map[string]bool)
+	for _, inbound := range p.Inbounds {
+		if service := inbound.Service(); service != "" {
+			services[service] = true
+		}
+	}
+	if service := p.GatewayTags[serviceTag]; service != "" {
+		services[service] = true
+	}
+	return sortedKeys(services)
+}
+
+// parseProxy convierte un Dataplane en un Proxy.
+func parseProxy(dp unstructured.Unstructured) Proxy {
+	proxy := Proxy{Name: dp.GetName(), Namespace: dp.GetNamespace(), Mesh: meshOf(dp)}
+	inbounds, _, _ := unstructured.NestedSlice(dp.Object, "spec", "networking", "inbound")
+	for _, item := range inbounds {
+		inboundMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		port, _, _ := unstructured.NestedInt64(inboundMap, "port")
+		tags, _, _ := unstructured.NestedStringMap(inboundMap, "tags")
+		proxy.Inbounds = append(proxy.Inbounds, Inbound{Port: port, Tags: tags})
+	}
+	if gateway, found, _ := unstructured.NestedMap(dp.Object, "spec", "networking", "gateway"); found {
+		tags, _, _ := unstructured.NestedStringMap(gateway, "tags")
+		if tags == nil {
+			tags = map[string]string{}
+		}
+		proxy.GatewayTags = tags
+	}
+	return proxy
+}
+
+// --- Resolver de targetRef ---
+
+// TargetResolver calcula a qué Dataplanes y servicios de un mesh se aplica un targetRef.
+// Es la única implementación de la semántica de targetRef: todos los analizadores de
+// cobertura se construyen sobre ella.
+type TargetResolver struct {
+	proxies []Proxy
+	// gatewaySelectors son los selectores ('spec.selectors[].match') de cada MeshGateway.
+	gatewaySelectors map[string][]map[string]string
+}
+
+// NewTargetResolver carga los Dataplanes (respetando env.Namespace) y los MeshGateways del
+// mesh de env. Si el CRD de MeshGateway no está instalado, los targetRef de ese tipo no
+// seleccionan nada.
+func NewTargetResolver(ctx context.Context, env *Env) (*TargetResolver, error) {
+	dataplanes, err := env.Source.List(ctx, DataplaneType, env.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error al listar Dataplanes: %w", err)
+	}
+	r := &TargetResolver{gatewaySelectors: make(map[string][]map[string]string)}
+	for _, dp := range filterByMesh(dataplanes, env.Mesh) {
+		r.proxies = append(r.proxies, parseProxy(dp))
+	}
+
+	gateways, err := env.Source.List(ctx, MeshGatewayType, "")
+	if err != nil && !IsNotInstalled(err) && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("error al listar MeshGateways: %w", err)
+	}
+	for _, gateway := range filterByMesh(gateways, env.Mesh) {
+		selectors, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "selectors")
+		for _, selector := range selectors {
+			selectorMap, ok := selector.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			match, _, _ := unstructured.NestedStringMap(selectorMap, "match")
+			r.gatewaySelectors[gateway.GetName()] = append(r.gatewaySelectors[gateway.GetName()], match)
+		}
+	}
+	return r, nil
+}
+
+// Proxies devuelve todos los Dataplanes del mesh.
+func (r *TargetResolver) Proxies() []Proxy {
+	return r.proxies
+}
+
+// AllServices devuelve el conjunto de servicios del mesh, incluidos los gateways.
+func (r *TargetResolver) AllServices() map[string]bool {
+	return r.services(true)
+}
+
+// SidecarServices devuelve los servicios del mesh expuestos por sidecars (sin gateways).
+func (r *TargetResolver) SidecarServices() map[string]bool {
+	return r.services(false)
+}
+
+func (r *TargetResolver) services(includeGateways bool) map[string]bool {
+	services := make(map[string]bool)
+	for _, proxy := range r.proxies {
+		if proxy.IsGateway() && !includeGateways {
+			continue
+		}
+		for _, service := range proxy.Services() {
+			services[service] = true
+		}
+	}
+	return services
+}
+
+// SelectProxies devuelve los Dataplanes seleccionados por un targetRef de primer nivel
+// ('spec.targetRef').
+func (r *TargetResolver) SelectProxies(ref TargetRef) []Proxy {
+	var selected []Proxy
+	for _, proxy := range r.proxies {
+		if r.SelectsProxy(ref, proxy) {
+			selected = append(selected, proxy)
+		}
+	}
+	return selected
+}
+
+// SelectsProxy indica si un targetRef de primer nivel selecciona un Dataplane concreto.
+func (r *TargetResolver) SelectsProxy(ref TargetRef, proxy Proxy) bool {
+	return len(r.matchingInbounds(ref, proxy)) > 0 || r.matchesGateway(ref, proxy)
+}
+
+// Proxy busca un Dataplane del resolver por namespace y nombre.
+func (r *TargetResolver) Proxy(namespace, name string) (Proxy, bool) {
+	for _, proxy := range r.proxies {
+		if proxy.Namespace == namespace && proxy.Name == name {
+			return proxy, true
+		}
+	}
+	return Proxy{}, false
+}
+
+// SelectServices devuelve los servicios seleccionados por un targetRef: los de los inbounds
+// (o gateways) que encajan con él. Sirve tanto para 'spec.targetRef' y 'from' (servicios
+// que reciben el tráfico) como para 'to' (servicios de destino).
+func (r *TargetResolver) SelectServices(ref TargetRef) map[string]bool {
+	services := make(map[string]bool)
+	for _, proxy := range r.proxies {
+		for _, service := range r.SelectProxyServices(ref, proxy) {
+			services[service] = true
+		}
+	}
+	return services
+}
+
+// SelectProxyServices devuelve, en orden, los servicios de un Dataplane concreto que
+// selecciona un targetRef.
+func (r *TargetResolver) SelectProxyServices(ref TargetRef, proxy Proxy) []string {
+	services := make(map[string]bool)
+	for _, inbound := range r.matchingInbounds(ref, proxy) {
+		if service := inbound.Service(); service != "" {
+			services[service] = true
+		}
+	}
+	if r.matchesGateway(ref, proxy) {
+		if service := proxy.GatewayTags[serviceTag]; service != "" {
+			services[service] = true
+		}
+	}
+	return sortedKeys(services)
+}
+
+// ServicesNamed devuelve los servicios del mesh que se llaman name: por su kuma.io/service o,
+// en Kubernetes, por el nombre del Service en cualquier namespace. Sirve para resolver nombres
+// que escribe el usuario, no targetRefs.
+func (r *TargetResolver) ServicesNamed(name string) map[string]bool {
+	services := make(map[string]bool)
+	for _, proxy := range r.proxies {
+		for _, inbound := range proxy.Inbounds {
+			if inbound.Tags[serviceTag] == name || inbound.Tags[k8sServiceNameTag] == name {
+				services[inbound.Service()] = true
+			}
+		}
+		if proxy.GatewayTags[serviceTag] == name || proxy.GatewayTags[k8sServiceNameTag] == name {
+			services[proxy.GatewayTags[serviceTag]] = true
+		}
+	}
+	delete(services, "")
+	return services
+}
+
+// MatchesService indica si un targetRef selecciona un servicio concreto del mesh.
+func (r *TargetResolver) MatchesService(ref TargetRef, service string) bool {
+	return r.SelectServices(ref)[service]
+}
+
+// selectedInbounds devuelve los inbounds (y gateways) del mesh que selecciona un targetRef de
+// primer nivel, identificados como "<namespace>/<dataplane>:<puerto>" o
+// "<namespace>/<dataplane>:gateway". Permite comparar selectores con más precisión que por
+// servicio cuando los inbounds de un mismo servicio tienen etiquetas distintas.
+func (r *TargetResolver) selectedInbounds(ref TargetRef) map[string]bool {
+	selected := make(map[string]bool)
+	for _, proxy := range r.proxies {
+		for _, inbound := range r.matchingInbounds(ref, proxy) {
+			selected[fmt.Sprintf("%s/%s:%d", proxy.Namespace, proxy.Name, inbound.Port)] = true
+		}
+		if r.matchesGateway(ref, proxy) {
+			selected[fmt.Sprintf("%s/%s:gateway", proxy.Namespace, proxy.Name)] = true
+		}
+	}
+	return selected
+}
+
+// matchingInbounds devuelve los inbounds de un sidecar que selecciona el targetRef.
+func (r *TargetResolver) matchingInbounds(ref TargetRef, proxy Proxy) []Inbound {
+	if proxy.IsGateway() || !allowsProxyType(ref, "Sidecar") {
+		return nil
+	}
+	var matched []Inbound
+	for _, inbound := range proxy.Inbounds {
+		if inboundMatches(ref, proxy, inbound) {
+			matched = append(matched, inbound)
+		}
+	}
+	return matched
+}
+
+func inboundMatches(ref TargetRef, proxy Proxy, inbound Inbound) bool {
+	switch ref.Kind {
+	case TargetMesh:
+		return true
+	case TargetMeshSubset:
+		return tagsMatch(ref.Tags, inbound.Tags)
+	case TargetMeshService:
+		return serviceMatches(ref, proxy, inbound.Tags)
+	case TargetMeshServiceSubset:
+		return serviceMatches(ref, proxy, inbound.Tags) && tagsMatch(ref.Tags, inbound.Tags)
+	default:
+		return false
+	}
+}
+
+// matchesGateway indica si el targetRef selecciona un Dataplane de tipo gateway.
+func (r *TargetResolver) matchesGateway(ref TargetRef, proxy Proxy) bool {
+	if !proxy.IsGateway() || !allowsProxyType(ref, "Gateway") {
+		return false
+	}
+	switch ref.Kind {
+	case TargetMesh:
+		return true
+	case TargetMeshSubset:
+		return tagsMatch(ref.Tags, proxy.GatewayTags)
+	case TargetMeshService:
+		return serviceMatches(ref, proxy, proxy.GatewayTags)
+	case TargetMeshServiceSubset:
+		return serviceMatches(ref, proxy, proxy.GatewayTags) && tagsMatch(ref.Tags, proxy.GatewayTags)
+	case TargetMeshGateway:
+		for _, match := range r.gatewaySelectors[ref.Name] {
+			if tagsMatch(match, proxy.GatewayTags) {
+				return tagsMatch(ref.Tags, proxy.GatewayTags)
+			}
+		}
+	}
+	return false
+}
+
+// serviceMatches compara el nombre de un targetRef MeshService con las etiquetas de un
+// inbound. Se acepta el valor de kuma.io/service ("backend_kuma-demo_svc_3001") y, en
+// Kubernetes, el nombre del Service ("backend") en el namespace del targetRef o, si no lo
+// indica, en el de la política. Sin namespace, el nombre corto no selecciona nada.
+func serviceMatches(ref TargetRef, proxy Proxy, tags map[string]string) bool {
+	if ref.Name == "" {
+		return false
+	}
+	if tags[serviceTag] == ref.Name && ref.Namespace == "" {
+		return true
+	}
+	if tags[k8sServiceNameTag] != ref.Name || ref.namespace() == "" {
+		return false
+	}
+	return inboundNamespace(proxy, tags) == ref.namespace()
+}
+
+// inboundNamespace devuelve el namespace del Service de un inbound: el de la etiqueta
+// k8s.kuma.io/namespace o, si no la tiene, el del Dataplane.
+func inboundNamespace(proxy Proxy, tags map[string]string) string {
+	if namespace := tags[k8sNamespaceTag]; namespace != "" {
+		return namespace
+	}
+	return proxy.Namespace
+}
+
+// tagsMatch indica si todas las etiquetas del selector están presentes en tags.
+func tagsMatch(selector, tags map[string]string) bool {
+	for key, value := range selector {
+		if tags[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func allowsProxyType(ref TargetRef, proxyType string) bool {
+	if len(ref.ProxyTypes) == 0 {
+		return true
+	}
+	for _, t := range ref.ProxyTypes {
+		if t == proxyType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,301 @@
+// pkg/analysis/targetref_test.go
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// fakeSource es un Source en memoria con los objetos de unos manifiestos YAML.
+type fakeSource struct {
+	objects []unstructured.Unstructured
+}
+
+// newFakeSource decodifica manifiestos YAML (varios documentos separados por '---').
+func newFakeSource(t *testing.T, manifests string) *fakeSource {
+	t.Helper()
+	source := &fakeSource{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifests), 4096)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return source
+			}
+			t.Fatalf("manifiesto inválido: %v", err)
+		}
+		// utiljson conserva los enteros como int64, igual que el API server.
+		var doc map[string]interface{}
+		if err := utiljson.Unmarshal(raw, &doc); err != nil {
+			t.Fatalf("manifiesto inválido: %v", err)
+		}
+		if len(doc) > 0 {
+			source.objects = append(source.objects, unstructured.Unstructured{Object: doc})
+		}
+	}
+}
+
+func (s *fakeSource) List(_ context.Context, rt ResourceType, namespace string) ([]unstructured.Unstructured, error) {
+	var items []unstructured.Unstructured
+	for _, obj := range s.objects {
+		if obj.GetKind() == rt.Kind && (namespace == "" || obj.GetNamespace() == namespace) {
+			items = append(items, *obj.DeepCopy())
+		}
+	}
+	return items, nil
+}
+
+func (s *fakeSource) Get(ctx context.Context, rt ResourceType, namespace, name string) (*unstructured.Unstructured, error) {
+	items, _ := s.List(ctx, rt, namespace)
+	for i := range items {
+		if items[i].GetName() == name {
+			return &items[i], nil
+		}
+	}
+	return nil, apierrors.NewNotFound(rt.GVR.GroupResource(), name)
+}
+
+// targetRefDataplanes son los Dataplanes con los que se prueba el resolver: un Service 'web',
+// un Service 'backend' con dos versiones en demo, otro 'backend' en el namespace other y un
+// gateway builtin.
+const targetRefDataplanes = `
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: web-1, namespace: demo, labels: {kuma.io/mesh: default}}
+spec:
+  networking:
+    inbound:
+    - port: 80
+      tags: {kuma.io/service: web_demo_svc_80, k8s.kuma.io/service-name: web, k8s.kuma.io/namespace: demo, team: front}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: backend-1, namespace: demo, labels: {kuma.io/mesh: default}}
+spec:
+  networking:
+    inbound:
+    - port: 3001
+      tags: {kuma.io/service: backend_demo_svc_3001, k8s.kuma.io/service-name: backend, k8s.kuma.io/namespace: demo, version: v1}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: backend-2, namespace: demo, labels: {kuma.io/mesh: default}}
+spec:
+  networking:
+    inbound:
+    - port: 3001
+      tags: {kuma.io/service: backend_demo_svc_3001, k8s.kuma.io/service-name: backend, k8s.kuma.io/namespace: demo, version: v2}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: backend-1, namespace: other, labels: {kuma.io/mesh: default}}
+spec:
+  networking:
+    inbound:
+    - port: 3001
+      tags: {kuma.io/service: backend_other_svc_3001, k8s.kuma.io/service-name: backend, k8s.kuma.io/namespace: other}
+---
+apiVersion: kuma.io/v1alpha1
+kind: Dataplane
+metadata: {name: edge-1, namespace: kuma-system, labels: {kuma.io/mesh: default}}
+spec:
+  networking:
+    gateway:
+      type: BUILTIN
+      tags: {kuma.io/service: edge-gateway, zone: a}
+---
+apiVersion: kuma.io/v1alpha1
+kind: MeshGateway
+metadata: {name: edge, labels: {kuma.io/mesh: default}}
+spec:
+  selectors:
+  - match: {kuma.io/service: edge-gateway}
+`
+
+func TestTargetResolverSelect(t *testing.T) {
+	env := &Env{Source: newFakeSource(t, targetRefDataplanes), Mesh: "default"}
+	resolver, err := NewTargetResolver(context.Background(), env)
+	if err != nil {
+		t.Fatalf("NewTargetResolver: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		ref      TargetRef
+		services []string
+		proxies  []string
+	}{
+		{
+			name:     "Mesh",
+			ref:      TargetRef{Kind: TargetMesh},
+			services: []string{"backend_demo_svc_3001", "backend_other_svc_3001", "edge-gateway", "web_demo_svc_80"},
+			proxies:  []string{"demo/web-1", "demo/backend-1", "demo/backend-2", "other/backend-1", "kuma-system/edge-1"},
+		},
+		{
+			name:     "Mesh solo sidecars",
+			ref:      TargetRef{Kind: TargetMesh, ProxyTypes: []string{"Sidecar"}},
+			services: []string{"backend_demo_svc_3001", "backend_other_svc_3001", "web_demo_svc_80"},
+			proxies:  []string{"demo/web-1", "demo/backend-1", "demo/backend-2", "other/backend-1"},
+		},
+		{
+			name:     "MeshSubset",
+			ref:      TargetRef{Kind: TargetMeshSubset, Tags: map[string]string{"team": "front"}},
+			services: []string{"web_demo_svc_80"},
+			proxies:  []string{"demo/web-1"},
+		},
+		{
+			name:     "MeshSubset sin coincidencias",
+			ref:      TargetRef{Kind: TargetMeshSubset, Tags: map[string]string{"team": "back"}},
+			services: []string{},
+		},
+		{
+			name:     "MeshService por kuma.io/service",
+			ref:      TargetRef{Kind: TargetMeshService, Name: "backend_demo_svc_3001"},
+			services: []string{"backend_demo_svc_3001"},
+			proxies:  []string{"demo/backend-1", "demo/backend-2"},
+		},
+		{
+			name:     "MeshService por nombre corto sin namespace",
+			ref:      TargetRef{Kind: TargetMeshService, Name: "backend"},
+			services: []string{},
+		},
+		{
+			name:     "MeshService por nombre corto en el namespace de la política",
+			ref:      TargetRef{Kind: TargetMeshService, Name: "backend", policyNamespace: "demo"},
+			services: []string{"backend_demo_svc_3001"},
+			proxies:  []string{"demo/backend-1", "demo/backend-2"},
+		},
+		{
+			name:     "el namespace del targetRef manda sobre el de la política",
+			ref:      TargetRef{Kind: TargetMeshService, Name: "backend", Namespace: "other", policyNamespace: "demo"},
+			services: []string{"backend_other_svc_3001"},
+			proxies:  []string{"other/backend-1"},
+		},
+		{
+			name:     "MeshService por nombre corto y namespace",
+			ref:      TargetRef{Kind: TargetMeshService, Name: "backend", Namespace: "other"},
+			services: []string{"backend_other_svc_3001"},
+			proxies:  []string{"other/backend-1"},
+		},
+		{
+			name:     "MeshService por kuma.io/service con namespace",
+			ref:      TargetRef{Kind: TargetMeshService, Name: "backend_demo_svc_3001", Namespace: "demo"},
+			services: []string{},
+		},
+		{
+			name:     "MeshServiceSubset",
+			ref:      TargetRef{Kind: TargetMeshServiceSubset, Name: "backend_demo_svc_3001", Tags: map[string]string{"version": "v2"}},
+			services: []string{"backend_demo_svc_3001"},
+			proxies:  []string{"demo/backend-2"},
+		},
+		{
+			name:     "MeshServiceSubset por nombre corto",
+			ref:      TargetRef{Kind: TargetMeshServiceSubset, Name: "backend", Namespace: "demo", Tags: map[string]string{"version": "v1"}},
+			services: []string{"backend_demo_svc_3001"},
+			proxies:  []string{"demo/backend-1"},
+		},
+		{
+			name:     "MeshGateway",
+			ref:      TargetRef{Kind: TargetMeshGateway, Name: "edge"},
+			services: []string{"edge-gateway"},
+			proxies:  []string{"kuma-system/edge-1"},
+		},
+		{
+			name:     "MeshGateway con etiquetas que no tiene",
+			ref:      TargetRef{Kind: TargetMeshGateway, Name: "edge", Tags: map[string]string{"zone": "b"}},
+			services: []string{},
+		},
+		{
+			name:     "MeshGateway inexistente",
+			ref:      TargetRef{Kind: TargetMeshGateway, Name: "missing"},
+			services: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sortedKeys(resolver.SelectServices(tt.ref)); !reflect.DeepEqual(got, tt.services) {
+				t.Errorf("SelectServices(%s) = %v, se esperaba %v", tt.ref, got, tt.services)
+			}
+			var proxies []string
+			for _, proxy := range resolver.SelectProxies(tt.ref) {
+				proxies = append(proxies, ResourceRef{Namespace: proxy.Namespace, Name: proxy.Name}.String())
+			}
+			if !reflect.DeepEqual(proxies, tt.proxies) {
+				t.Errorf("SelectProxies(%s) = %v, se esperaba %v", tt.ref, proxies, tt.proxies)
+			}
+		})
+	}
+}
+
+func TestPolicyTargetRef(t *testing.T) {
+	source := newFakeSource(t, `
+apiVersion: kuma.io/v1alpha1
+kind: MeshTimeout
+metadata: {name: backend, namespace: demo, labels: {kuma.io/mesh: default}}
+spec:
+  targetRef: {kind: MeshService, name: backend}
+  to:
+  - targetRef: {kind: MeshService, name: db, namespace: other}
+  - targetRef: {kind: Mesh}
+`)
+	policies, _ := source.List(context.Background(), MeshTimeoutType, "")
+	policy := policies[0]
+
+	if got, want := policyTargetRef(policy), (TargetRef{Kind: TargetMeshService, Name: "backend", policyNamespace: "demo"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("policyTargetRef = %#v, se esperaba %#v", got, want)
+	}
+	var refs []TargetRef
+	for _, rule := range policyRules(policy, "to") {
+		refs = append(refs, rule.TargetRef)
+	}
+	want := []TargetRef{{Kind: TargetMeshService, Name: "db", Namespace: "other"}, {Kind: TargetMesh}}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("policyRules(to) = %#v, se esperaba %#v", refs, want)
+	}
+}
+
+func TestParseTargetRef(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  map[string]interface{}
+		want TargetRef
+	}{
+		{
+			name: "ausente equivale a Mesh",
+			obj:  map[string]interface{}{},
+			want: TargetRef{Kind: TargetMesh},
+		},
+		{
+			name: "sin kind equivale a Mesh",
+			obj:  map[string]interface{}{"targetRef": map[string]interface{}{"proxyTypes": []interface{}{"Sidecar"}}},
+			want: TargetRef{Kind: TargetMesh, ProxyTypes: []string{"Sidecar"}},
+		},
+		{
+			name: "MeshService con namespace",
+			obj:  map[string]interface{}{"targetRef": map[string]interface{}{"kind": "MeshService", "name": "backend", "namespace": "demo"}},
+			want: TargetRef{Kind: TargetMeshService, Name: "backend", Namespace: "demo"},
+		},
+		{
+			name: "MeshSubset",
+			obj:  map[string]interface{}{"targetRef": map[string]interface{}{"kind": "MeshSubset", "tags": map[string]interface{}{"team": "front"}}},
+			want: TargetRef{Kind: TargetMeshSubset, Tags: map[string]string{"team": "front"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTargetRef(tt.obj, "targetRef"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTargetRef = %#v, se esperaba %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,120 @@
 // pkg/analysis/types.go
 package analysis
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // ValidationResult es una estructura genérica para contener los resultados de cualquier análisis.
 type ValidationResult struct {
-	Title       string        `json:"title"`
-	GeneratedAt time.Time     `json:"generatedAt"`
-	Findings    []interface{} `json:"findings"` // Usamos interface{} para poder guardar cualquier tipo de hallazgo.
+	Title       string         `json:"title"`
+	Mesh        string         `json:"mesh,omitempty"`
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Status      ResultStatus   `json:"status"`
+	Reason      string         `json:"reason,omitempty"`  // Por qué el análisis falló o se omitió.
+	Summary     *SummaryStatus `json:"summary,omitempty"` // Solo lo rellena el análisis de resumen general.
+	Findings    []Finding      `json:"findings"`
+	// Diagnostics son avisos sobre el propio análisis (p. ej. un tipo de política que no se pudo
+	// leer) que no invalidan el resultado pero lo dejan incompleto.
+	Diagnostics []string `json:"diagnostics,omitempty"`
+}
+
+// ResultStatus indica si un análisis se completó, falló o no llegó a ejecutarse.
+type ResultStatus string
+
+const (
+	StatusOK      ResultStatus = "ok"
+	StatusError   ResultStatus = "error"
+	StatusSkipped ResultStatus = "skipped"
+)
+
+// Failed indica si el análisis no produjo resultados (error u omitido).
+func (r *ValidationResult) Failed() bool {
+	return r.Status == StatusError || r.Status == StatusSkipped
+}
+
+// Severity es la gravedad de un hallazgo. Los valores están ordenados de menor a mayor,
+// por lo que se pueden comparar directamente (SeverityAlert > SeverityWarn).
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarn
+	SeverityAlert
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:  "INFO",
+	SeverityWarn:  "WARN",
+	SeverityAlert: "ALERT",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText serializa la severidad por su nombre ("INFO", "WARN", "ALERT") en JSON.
+func (s Severity) MarshalText() ([]byte, error) {
+	if _, ok := severityNames[s]; !ok {
+		return nil, fmt.Errorf("severidad desconocida: %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText permite leer la severidad desde su nombre.
+func (s *Severity) UnmarshalText(text []byte) error {
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// ParseSeverity convierte un nombre de severidad (sin distinguir mayúsculas) en su valor.
+func ParseSeverity(name string) (Severity, error) {
+	for severity, known := range severityNames {
+		if strings.EqualFold(known, name) {
+			return severity, nil
+		}
+	}
+	return 0, fmt.Errorf("severidad desconocida: %q", name)
+}
+
+// ResourceRef identifica el recurso de Kuma o Kubernetes al que se refiere un hallazgo.
+type ResourceRef struct {
+	Kind      string `json:"kind,omitempty"`
+	Mesh      string `json:"mesh,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// String devuelve una representación compacta del recurso, p. ej. "Dataplane/kuma-demo/redis-0".
+func (r ResourceRef) String() string {
+	var parts []string
+	for _, part := range []string{r.Kind, r.Namespace, r.Name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// Finding es un hallazgo individual de un análisis, con un ID de regla estable
+// (ver rules.go) para poder filtrarlo, suprimirlo o procesarlo desde otras herramientas.
+type Finding struct {
+	RuleID      string      `json:"ruleId"`
+	Severity    Severity    `json:"severity"`
+	Resource    ResourceRef `json:"resource"`
+	Message     string      `json:"message"`
+	Remediation string      `json:"remediation,omitempty"`
+	// Dataplane contiene el detalle del proxy en los hallazgos del análisis de dataplanes.
+	Dataplane *DataplaneStatus `json:"dataplane,omitempty"`
 }
 
 // DataplaneStatus contiene el estado de salud de un único Dataplane.
@@ -16,6 +123,39 @@ type DataplaneStatus struct {
 	Namespace string `json:"namespace"`
 	Status    string `json:"status"`
 	Details   string `json:"details"`
+	// Insight es el estado de la conexión xDS del proxy según su DataplaneInsight; nil si el
+	// control plane no ha publicado ninguno.
+	Insight *DataplaneInsightStatus `json:"insight,omitempty"`
+	// Inbounds y Outbounds solo se rellenan en la fila de estado del Dataplane, no en las de
+	// los hallazgos de su DataplaneInsight.
+	Inbounds  []InboundStatus  `json:"inbounds,omitempty"`
+	Outbounds []OutboundStatus `json:"outbounds,omitempty"`
+}
+
+// InboundStatus es un inbound de un Dataplane con su estado de salud.
+type InboundStatus struct {
+	Port    int64             `json:"port"`
+	Service string            `json:"service,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	// Ready refleja 'health.ready'; un inbound sin ese campo cuenta como no ready.
+	Ready bool `json:"ready"`
+}
+
+// OutboundStatus es un outbound de un Dataplane. Los Dataplanes antiguos identifican el
+// destino con tags (kuma.io/service) y los recientes con un backendRef.
+type OutboundStatus struct {
+	Address    string              `json:"address,omitempty"`
+	Port       int64               `json:"port"`
+	Tags       map[string]string   `json:"tags,omitempty"`
+	BackendRef *OutboundBackendRef `json:"backendRef,omitempty"`
+}
+
+// OutboundBackendRef es el destino de un outbound (MeshService, MeshExternalService...).
+type OutboundBackendRef struct {
+	Kind   string            `json:"kind"`
+	Name   string            `json:"name"`
+	Port   int64             `json:"port,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // SummaryStatus contiene los datos para el resumen general del mesh.
@@ -26,35 +166,30 @@ type SummaryStatus struct {
 	OfflineDataplanes  int `json:"offlineDataplanes"`
 	DegradedDataplanes int `json:"degradedDataplanes"`
 	InfoDataplanes     int `json:"infoDataplanes"`
+	StaleDataplanes    int `json:"staleDataplanes"`
 	TotalPolicies      int `json:"totalPolicies"`
 }
 
-// PolicyFinding representa un hallazgo (problema o información) sobre una política.
-type PolicyFinding struct {
-	Level    string `json:"level"` // "ALERT", "WARN", "INFO"
-	Message  string `json:"message"`
-	Resource string `json:"resource"` // El nombre del recurso asociado (servicio o política)
-}
-
-// MTLSFinding representa un hallazgo sobre la configuración de mTLS.
-type MTLSFinding struct {
-	Level    string `json:"level"` // "INFO", "WARN", "ALERT"
-	Message  string `json:"message"`
-	Resource string `json:"resource"` // El Mesh o la política específica
-}
-
-// ResilienceFinding representa un hallazgo sobre las políticas de resiliencia.
-type ResilienceFinding struct {
-	Level      string `json:"level"`      // "WARN", "INFO"
-	PolicyType string `json:"policyType"` // "MeshRetry", "MeshTimeout", etc.
-	Service    string `json:"service"`
-	Message    string `json:"message"`
+// HasErrors indica si algún análisis terminó con error.
+func HasErrors(results []*ValidationResult) bool {
+	for _, result := range results {
+		if result.Status == StatusError {
+			return true
+		}
+	}
+	return false
 }
 
-// ObservabilityFinding representa un hallazgo sobre las políticas de observabilidad.
-type ObservabilityFinding struct {
-	Level      string `json:"level"`      // "WARN", "INFO"
-	PolicyType string `json:"policyType"` // "MeshLog", "MeshMetric", "MeshTrace"
-	Resource   string `json:"resource"`   // El nombre de la política o "Global"
-	Message    string `json:"message"`
+// HighestSeverity devuelve la severidad más alta entre todos los hallazgos de los resultados.
+// El segundo valor es false si no hay ningún hallazgo.
+func HighestSeverity(results []*ValidationResult) (Severity, bool) {
+	highest, found := SeverityInfo, false
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			if !found || finding.Severity > highest {
+				highest, found = finding.Severity, true
+			}
+		}
+	}
+	return highest, found
 }
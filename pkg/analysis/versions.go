@@ -0,0 +1,246 @@
+// pkg/analysis/versions.go
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register(NewAnalyzer("version-skew", "Desfase de Versiones entre Control Plane y Dataplanes", CategoryDataplanes, AnalyzeVersionSkew, "versions"))
+}
+
+const versionSkewTitle = "Análisis de Desfase de Versiones (Control Plane / Dataplanes)"
+
+// ErrControlPlaneVersionUnsupported lo devuelven los orígenes que no pueden preguntar su
+// versión al control plane (clúster de Kubernetes, manifiestos exportados).
+var ErrControlPlaneVersionUnsupported = errors.New("el origen de datos no permite consultar la versión del control plane")
+
+// ControlPlaneVersioner es una interfaz opcional de Source para los orígenes que pueden
+// preguntar su versión al control plane, como la API REST en Universal, donde no hay un
+// Deployment del que leerla.
+type ControlPlaneVersioner interface {
+	ControlPlaneVersion(ctx context.Context) (string, error)
+}
+
+// maxMinorSkew es el desfase que admite Kuma: un kuma-dp puede ir hasta dos versiones menores
+// por detrás del control plane, nunca por delante.
+const maxMinorSkew = 2
+
+// maxListedDataplanes limita los Dataplanes que se nombran en cada grupo de versiones.
+const maxListedDataplanes = 5
+
+// kumaVersion es una versión semántica de Kuma (2.9.3, 2.10.0-preview...).
+type kumaVersion struct {
+	major, minor, patch int
+	raw                 string
+}
+
+// parseKumaVersion interpreta una versión con o sin 'v' y con o sin sufijo de pre-release.
+func parseKumaVersion(raw string) (kumaVersion, bool) {
+	core := strings.TrimPrefix(raw, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return kumaVersion{}, false
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return kumaVersion{}, false
+		}
+		numbers[i] = n
+	}
+	return kumaVersion{major: numbers[0], minor: numbers[1], patch: numbers[2], raw: raw}, true
+}
+
+func (v kumaVersion) less(other kumaVersion) bool {
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	if v.minor != other.minor {
+		return v.minor < other.minor
+	}
+	return v.patch < other.patch
+}
+
+// versionGroup son los Dataplanes de un mesh con la misma combinación de kuma-dp y Envoy.
+type versionGroup struct {
+	kumaDp     string
+	envoy      string
+	version    kumaVersion
+	parsed     bool
+	dataplanes []string
+}
+
+// AnalyzeVersionSkew compara la versión de kuma-dp de cada Dataplane (según su
+// DataplaneInsight) con la del control plane (según la imagen del Deployment
+// kuma-control-plane o, en Universal, según su API) y agrupa los proxies por versión, para
+// planificar los reinicios de sidecars tras actualizar el control plane.
+func AnalyzeVersionSkew(ctx context.Context, env *Env) (*ValidationResult, error) {
+	result := &ValidationResult{Title: versionSkewTitle, GeneratedAt: time.Now()}
+
+	cpVersion, reason, err := controlPlaneVersion(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		result.Status, result.Reason = StatusSkipped, reason
+		return result, nil
+	}
+
+	dataplanes, err := env.Source.List(ctx, DataplaneType, env.Namespace)
+	if err != nil {
+		if result, ok := skipIfNotInstalled(versionSkewTitle, err); ok {
+			return result, nil
+		}
+		return nil, fmt.Errorf("error al listar Dataplanes: %w", err)
+	}
+	dataplanes = filterByMesh(dataplanes, env.Mesh)
+	if len(dataplanes) == 0 {
+		return result, nil
+	}
+	insights, err := dataplaneInsights(ctx, env)
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, err.Error())
+		return result, nil
+	}
+
+	groups := make(map[string]*versionGroup)
+	for _, dp := range dataplanes {
+		var kumaDp, envoy string
+		if insight := insights[dataplaneKey(dp)]; insight != nil {
+			kumaDp, envoy = insight.KumaDpVersion, insight.EnvoyVersion
+		}
+		key := kumaDp + "|" + envoy
+		group, ok := groups[key]
+		if !ok {
+			group = &versionGroup{kumaDp: kumaDp, envoy: envoy}
+			group.version, group.parsed = parseKumaVersion(kumaDp)
+			groups[key] = group
+		}
+		group.dataplanes = append(group.dataplanes, ResourceRef{Namespace: dp.GetNamespace(), Name: dp.GetName()}.String())
+	}
+
+	for _, group := range sortVersionGroups(groups) {
+		result.Findings = append(result.Findings, versionGroupFinding(env.Mesh, group, cpVersion))
+	}
+	return result, nil
+}
+
+// controlPlaneVersion devuelve la versión del control plane: la etiqueta de la imagen del
+// Deployment kuma-control-plane o, si no existe (Universal), la que informa la API del
+// control plane. Si no se puede determinar, reason explica por qué.
+func controlPlaneVersion(ctx context.Context, env *Env) (version kumaVersion, reason string, err error) {
+	deployment, err := env.Source.Get(ctx, DeploymentType, controlPlaneNamespace, controlPlaneDeployment)
+	switch {
+	case err == nil:
+		image := controlPlaneImage(*deployment)
+		if version, ok := parseKumaVersion(imageTag(image)); ok {
+			return version, "", nil
+		}
+		return kumaVersion{}, fmt.Sprintf("la imagen del control plane (%s) no indica una versión de Kuma", image), nil
+	case !isMissing(err):
+		return kumaVersion{}, "", fmt.Errorf("error al leer el Deployment del control plane: %w", err)
+	}
+
+	versioner, ok := env.Source.(ControlPlaneVersioner)
+	if !ok {
+		return kumaVersion{}, controlPlaneMissingReason, nil
+	}
+	raw, err := versioner.ControlPlaneVersion(ctx)
+	switch {
+	case errors.Is(err, ErrControlPlaneVersionUnsupported):
+		return kumaVersion{}, controlPlaneMissingReason, nil
+	case err != nil:
+		return kumaVersion{}, "", fmt.Errorf("error al consultar la versión del control plane: %w", err)
+	}
+	if version, ok := parseKumaVersion(raw); ok {
+		return version, "", nil
+	}
+	return kumaVersion{}, fmt.Sprintf("la API del control plane informa una versión que no es de Kuma (%s)", orUnknown(raw)), nil
+}
+
+// imageTag devuelve la etiqueta de una referencia de imagen (registry:5000/kumahq/kuma-cp:2.9.3
+// -> 2.9.3), sin el digest.
+func imageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// sortVersionGroups ordena los grupos de la versión más antigua a la más nueva; los de
+// versión desconocida van al final.
+func sortVersionGroups(groups map[string]*versionGroup) []*versionGroup {
+	sorted := make([]*versionGroup, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.parsed != b.parsed {
+			return a.parsed
+		}
+		if a.parsed && (a.version.less(b.version) || b.version.less(a.version)) {
+			return a.version.less(b.version)
+		}
+		if a.kumaDp != b.kumaDp {
+			return a.kumaDp < b.kumaDp
+		}
+		return a.envoy < b.envoy
+	})
+	return sorted
+}
+
+func versionGroupFinding(mesh string, group *versionGroup, cp kumaVersion) Finding {
+	ref := ResourceRef{Kind: "kuma-dp", Mesh: mesh, Name: group.kumaDp}
+	subject := fmt.Sprintf("%d Dataplane(s) con kuma-dp %s (Envoy %s)", len(group.dataplanes), group.kumaDp, orUnknown(group.envoy))
+	listed := listDataplanes(group.dataplanes)
+
+	if !group.parsed {
+		ref.Name = orUnknown(group.kumaDp)
+		if group.kumaDp == "" {
+			subject = fmt.Sprintf("%d Dataplane(s) que no informan de su versión (sin DataplaneInsight o sin suscripción xDS)", len(group.dataplanes))
+		}
+		return RuleDataplaneVersionUnknown.Finding(ref, fmt.Sprintf("%s: %s.", subject, listed))
+	}
+
+	behind := cp.minor - group.version.minor
+	switch {
+	case group.version.major < cp.major:
+		return RuleDataplaneVersionUnsupported.Finding(ref, fmt.Sprintf(
+			"%s: versión mayor anterior a la del control plane %s: %s.", subject, cp.raw, listed))
+	case group.version.major > cp.major || behind < 0:
+		return RuleDataplaneVersionAhead.Finding(ref, fmt.Sprintf(
+			"%s: más nueva que el control plane %s: %s.", subject, cp.raw, listed))
+	case behind > maxMinorSkew:
+		return RuleDataplaneVersionUnsupported.Finding(ref, fmt.Sprintf(
+			"%s: %d versiones menores por detrás del control plane %s (el máximo soportado es %d): %s.", subject, behind, cp.raw, maxMinorSkew, listed))
+	case behind > 0:
+		return RuleDataplaneVersionBehind.Finding(ref, fmt.Sprintf(
+			"%s: %d versión(es) menor(es) por detrás del control plane %s, dentro del desfase soportado: %s.", subject, behind, cp.raw, listed))
+	default:
+		return RuleDataplaneVersionCurrent.Finding(ref, fmt.Sprintf(
+			"%s: misma versión menor que el control plane %s.", subject, cp.raw))
+	}
+}
+
+// listDataplanes nombra los primeros Dataplanes de un grupo y resume el resto.
+func listDataplanes(names []string) string {
+	if len(names) <= maxListedDataplanes {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s y %d más", strings.Join(names[:maxListedDataplanes], ", "), len(names)-maxListedDataplanes)
+}
@@ -0,0 +1,119 @@
+// pkg/analysis/versions_test.go
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestParseKumaVersion(t *testing.T) {
+	tests := []struct {
+		raw                 string
+		major, minor, patch int
+		ok                  bool
+	}{
+		{raw: "2.9.3", major: 2, minor: 9, patch: 3, ok: true},
+		{raw: "v2.10.0", major: 2, minor: 10, ok: true},
+		{raw: "2.10.0-preview.v1a2b3c", major: 2, minor: 10, ok: true},
+		{raw: "2.9.1+build.5", major: 2, minor: 9, patch: 1, ok: true},
+		{raw: "2.9", major: 2, minor: 9, ok: true},
+		{raw: "2"},
+		{raw: "2.9.3.1"},
+		{raw: "latest"},
+		{raw: "2.x.0"},
+		{raw: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, ok := parseKumaVersion(tt.raw)
+			if ok != tt.ok {
+				t.Fatalf("parseKumaVersion(%q) ok = %v, se esperaba %v", tt.raw, ok, tt.ok)
+			}
+			if ok && (got.major != tt.major || got.minor != tt.minor || got.patch != tt.patch) {
+				t.Errorf("parseKumaVersion(%q) = %d.%d.%d, se esperaba %d.%d.%d", tt.raw, got.major, got.minor, got.patch, tt.major, tt.minor, tt.patch)
+			}
+		})
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "kumahq/kuma-cp:2.9.3", want: "2.9.3"},
+		{image: "registry:5000/kumahq/kuma-cp:2.9.3", want: "2.9.3"},
+		{image: "registry:5000/kumahq/kuma-cp", want: ""},
+		{image: "kumahq/kuma-cp:2.9.3@sha256:0123abcd", want: "2.9.3"},
+		{image: "kumahq/kuma-cp@sha256:0123abcd", want: ""},
+		{image: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := imageTag(tt.image); got != tt.want {
+				t.Errorf("imageTag(%q) = %q, se esperaba %q", tt.image, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionGroupFinding(t *testing.T) {
+	cp, _ := parseKumaVersion("2.9.3")
+	tests := []struct {
+		kumaDp string
+		want   Rule
+	}{
+		{kumaDp: "2.9.0", want: RuleDataplaneVersionCurrent},
+		{kumaDp: "2.8.5", want: RuleDataplaneVersionBehind},
+		{kumaDp: fmt.Sprintf("2.%d.0", 9-maxMinorSkew), want: RuleDataplaneVersionBehind},
+		{kumaDp: fmt.Sprintf("2.%d.0", 9-maxMinorSkew-1), want: RuleDataplaneVersionUnsupported},
+		{kumaDp: "1.8.0", want: RuleDataplaneVersionUnsupported},
+		{kumaDp: "2.10.0", want: RuleDataplaneVersionAhead},
+		{kumaDp: "3.0.0", want: RuleDataplaneVersionAhead},
+		{kumaDp: "dev", want: RuleDataplaneVersionUnknown},
+		{kumaDp: "", want: RuleDataplaneVersionUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kumaDp, func(t *testing.T) {
+			group := &versionGroup{kumaDp: tt.kumaDp, envoy: "1.31.2", dataplanes: []string{"demo/web-1"}}
+			group.version, group.parsed = parseKumaVersion(tt.kumaDp)
+			if got := versionGroupFinding("default", group, cp); got.RuleID != tt.want.ID {
+				t.Errorf("kuma-dp %q con el control plane %s: %s, se esperaba %s (%s)", tt.kumaDp, cp.raw, got.RuleID, tt.want.ID, got.Message)
+			}
+		})
+	}
+}
+
+func TestControlPlaneVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		image  string // vacía si no hay Deployment del control plane
+		want   string
+		reason bool
+	}{
+		{name: "imagen con versión", image: "kumahq/kuma-cp:2.9.3", want: "2.9.3"},
+		{name: "imagen sin versión", image: "kumahq/kuma-cp:latest", reason: true},
+		{name: "sin Deployment", reason: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifests := ""
+			if tt.image != "" {
+				manifests = `
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: kuma-control-plane, namespace: kuma-system}
+spec: {template: {spec: {containers: [{name: control-plane, image: "` + tt.image + `"}]}}}
+`
+			}
+			version, reason, err := controlPlaneVersion(context.Background(), &Env{Source: newFakeSource(t, manifests)})
+			if err != nil {
+				t.Fatalf("controlPlaneVersion: %v", err)
+			}
+			if (reason != "") != tt.reason || version.raw != tt.want {
+				t.Errorf("controlPlaneVersion = %q (motivo %q), se esperaba %q", version.raw, reason, tt.want)
+			}
+		})
+	}
+}